@@ -0,0 +1,103 @@
+package main
+
+import (
+	"fmt"
+)
+
+const (
+	// AlertExtractorDepleted an Extractor has no resource left to mine
+	AlertExtractorDepleted int = iota
+	// AlertChestFull a Chest cannot accept any more Product-s
+	AlertChestFull
+	// AlertFactoryNoRecipe a Factory has no Recipe to produce
+	AlertFactoryNoRecipe
+	// AlertInventoryFull the inventory of the player cannot hold any more Product-s
+	AlertInventoryFull
+)
+
+// alertKey identifies an Alert, a Structure raises each kind of Alert at most once
+type alertKey struct {
+	kind int
+	s    Structure
+}
+
+// Alert a condition the player should look into, raised by the Game while it lasts
+type Alert struct {
+	kind int
+	s    Structure
+	pos  position
+}
+
+// Message returns the human readable description of the Alert
+func (a *Alert) Message() string {
+	switch a.kind {
+	case AlertExtractorDepleted:
+		return "depleted"
+	case AlertChestFull:
+		return "chest full"
+	case AlertFactoryNoRecipe:
+		return "no recipe"
+	case AlertInventoryFull:
+		return "inventory full"
+	}
+
+	return "unknown"
+}
+
+// Source returns the position of the Structure that raised the Alert, false if it has no source
+func (a *Alert) Source() (position, bool) {
+	return a.pos, a.s != nil
+}
+
+func (a *Alert) String() string {
+	if a.s == nil {
+		return a.Message()
+	}
+
+	return fmt.Sprintf("%s %d,%d", a.Message(), a.pos.x, a.pos.y)
+}
+
+// raiseAlert signals that the condition of the Alert holds during the current tick
+func (g *Game) raiseAlert(kind int, s Structure, pos position) {
+	g.raised[alertKey{kind, s}] = &Alert{kind, s, pos}
+}
+
+// updateAlerts keeps the alerts raised during the current tick, the ones raised before keep their order
+func (g *Game) updateAlerts() {
+	if g.inventory.Size() >= g.inventory.Capacity() {
+		g.raiseAlert(AlertInventoryFull, nil, position{})
+	}
+
+	alerts := make([]*Alert, 0, len(g.raised))
+	for _, a := range g.alerts {
+		key := alertKey{a.kind, a.s}
+		if _, ok := g.raised[key]; ok {
+			alerts = append(alerts, a)
+			delete(g.raised, key)
+		}
+	}
+
+	for _, a := range g.raised {
+		alerts = append(alerts, a)
+	}
+
+	g.alerts = alerts
+	g.raised = make(map[alertKey]*Alert)
+}
+
+// Alerts returns the active alerts, from the oldest to the newest
+func (g *Game) Alerts() []*Alert {
+	return g.alerts
+}
+
+// CountAlerts returns the number of active alerts of the kind
+func (g *Game) CountAlerts(kind int) int {
+	count := 0
+	for _, a := range g.alerts {
+		if a.kind == kind {
+			count++
+		}
+	}
+
+	return count
+}
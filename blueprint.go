@@ -0,0 +1,463 @@
+package main
+
+import (
+	"bufio"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+const (
+	// blueprintPrefix identifies the text representation of a Blueprint and its format version
+	blueprintPrefix = "GI2:"
+	// blueprintNoRecipe the recipe of a Structure that is not a Factory, or of a Factory without a Recipe
+	blueprintNoRecipe = "-"
+	// blueprintLibraryFile the name of the file storing the BlueprintLibrary
+	blueprintLibraryFile = "blueprints.txt"
+)
+
+// BlueprintEntry one Structure of a Blueprint and its offset from the top left corner
+type BlueprintEntry struct {
+	x, y      int
+	structure Structure
+}
+
+// Blueprint a saved layout of Structure-s that can be placed on the map at once
+type Blueprint struct {
+	name          string
+	width, height int
+	entries       []BlueprintEntry
+}
+
+// Name returns the name of the Blueprint
+func (b *Blueprint) Name() string {
+	return b.name
+}
+
+// Entries returns the Structure-s of the Blueprint
+func (b *Blueprint) Entries() []BlueprintEntry {
+	return b.entries
+}
+
+// Requirements returns the Product-s needed to build the Blueprint
+func (b *Blueprint) Requirements() map[*Product]int {
+	requirements := make(map[*Product]int)
+	for _, entry := range b.entries {
+		requirements[GlobalProductFactory.GetProduct(entry.structure.GetCode())]++
+	}
+
+	return requirements
+}
+
+// CopyBlueprint creates a deep copy of the Blueprint
+func (b *Blueprint) CopyBlueprint() *Blueprint {
+	blueprint := &Blueprint{b.name, b.width, b.height, make([]BlueprintEntry, len(b.entries))}
+	for i, entry := range b.entries {
+		blueprint.entries[i] = BlueprintEntry{entry.x, entry.y, entry.structure.CopyStructure()}
+	}
+
+	return blueprint
+}
+
+// RotateRight rotates the whole Blueprint clockwise
+func (b *Blueprint) RotateRight() {
+	for i, entry := range b.entries {
+		height := len(entry.structure.Tiles())
+
+		b.entries[i].x = b.height - entry.y - height
+		b.entries[i].y = entry.x
+		rotateQuarter(entry.structure, true)
+	}
+
+	b.width, b.height = b.height, b.width
+}
+
+// RotateLeft rotates the whole Blueprint counter clockwise
+func (b *Blueprint) RotateLeft() {
+	for i, entry := range b.entries {
+		width := len(entry.structure.Tiles()[0])
+
+		b.entries[i].x = entry.y
+		b.entries[i].y = b.width - entry.x - width
+		rotateQuarter(entry.structure, false)
+	}
+
+	b.width, b.height = b.height, b.width
+}
+
+// rotateQuarter turns the Structure by 90 degrees, belts need 3 rotation steps for that
+func rotateQuarter(s Structure, right bool) {
+	steps := 1
+	if _, ok := s.(*Belt); ok {
+		steps = 3
+	}
+
+	for i := 0; i < steps; i++ {
+		if right {
+			s.RotateRight()
+		} else {
+			s.RotateLeft()
+		}
+	}
+}
+
+// rotationOf returns how many RotateRight calls turn the default Structure into the specified one
+func rotationOf(s Structure) int {
+	if b, ok := s.(*Belt); ok {
+		return b.RotationPosition
+	}
+
+	template := GlobalProductFactory.GetProduct(s.GetCode()).structure.CopyStructure()
+	for i := 0; i < 4; i++ {
+		if sameOrientation(template, s) {
+			return i
+		}
+		template.RotateRight()
+	}
+
+	return 0
+}
+
+// sameOrientation compares the transfer points of two Structure-s of the same type, their tiles
+// may display different Product-s so they are only compared by size
+func sameOrientation(a, b Structure) bool {
+	aTiles, bTiles := a.Tiles(), b.Tiles()
+	if len(aTiles) != len(bTiles) || len(aTiles[0]) != len(bTiles[0]) {
+		return false
+	}
+
+	if !sameTransfers(a.Inputs(), b.Inputs()) || !sameTransfers(a.Outputs(), b.Outputs()) {
+		return false
+	}
+
+	aFluid, aOk := a.(FluidStructure)
+	bFluid, bOk := b.(FluidStructure)
+	if aOk && bOk {
+		return sameTransfers(aFluid.FluidConnections(), bFluid.FluidConnections())
+	}
+
+	return true
+}
+
+func sameTransfers(a, b []Transfer) bool {
+	if len(a) != len(b) {
+		return false
+	}
+
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+
+	return true
+}
+
+// recipeByOutput returns the Recipe of the Assembly producing the named Product, nil if there is none
+func recipeByOutput(name string) *Recipe {
+	for _, recipe := range GlobalRecipeFactory.Assembly {
+		if recipe.output.name == name {
+			return recipe
+		}
+	}
+
+	return nil
+}
+
+// String returns the text representation of the Blueprint, used to share it between players; the Structure-s
+// and the Recipe-s are named so that the text keeps its meaning when Product-s are added
+func (b *Blueprint) String() string {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "%d,%d", b.width, b.height)
+
+	for _, entry := range b.entries {
+		recipe := blueprintNoRecipe
+		if f, ok := entry.structure.(*Factory); ok && f.recipe != nil {
+			recipe = f.recipe.output.name
+		}
+
+		name := GlobalProductFactory.GetProduct(entry.structure.GetCode()).name
+		fmt.Fprintf(&sb, ";%d,%d,%s,%d,%s", entry.x, entry.y, name, rotationOf(entry.structure), recipe)
+	}
+
+	return blueprintPrefix + base64.RawURLEncoding.EncodeToString([]byte(sb.String()))
+}
+
+// ParseBlueprint creates a Blueprint from its text representation
+func ParseBlueprint(name, text string) (*Blueprint, error) {
+	if !strings.HasPrefix(text, blueprintPrefix) {
+		return nil, errors.New("unknown blueprint format")
+	}
+
+	data, err := base64.RawURLEncoding.DecodeString(strings.TrimPrefix(text, blueprintPrefix))
+	if err != nil {
+		return nil, fmt.Errorf("invalid blueprint encoding: %v", err)
+	}
+
+	parts := strings.Split(string(data), ";")
+	size, err := parseInts(parts[0], 2)
+	if err != nil {
+		return nil, err
+	}
+
+	b := &Blueprint{name, size[0], size[1], make([]BlueprintEntry, 0, len(parts)-1)}
+	for _, part := range parts[1:] {
+		fields := strings.Split(part, ",")
+		if len(fields) != 5 {
+			return nil, fmt.Errorf("expected 5 values, found %q", part)
+		}
+
+		values := make([]int, 0, 3)
+		for _, field := range []string{fields[0], fields[1], fields[3]} {
+			value, err := strconv.Atoi(field)
+			if err != nil {
+				return nil, fmt.Errorf("invalid number %q", field)
+			}
+			values = append(values, value)
+		}
+
+		x, y, rotation := values[0], values[1], values[2]
+
+		product := GlobalProductFactory.ProductByName(fields[2])
+		if product == nil || product.structure == nil {
+			return nil, fmt.Errorf("unknown structure %q", fields[2])
+		}
+
+		s := product.structure.CopyStructure()
+		for i := 0; i < rotation%12; i++ {
+			s.RotateRight()
+		}
+
+		if f, ok := s.(*Factory); ok && fields[4] != blueprintNoRecipe {
+			recipe := recipeByOutput(fields[4])
+			if recipe == nil {
+				return nil, fmt.Errorf("unknown recipe %q", fields[4])
+			}
+			f.SetRecipe(recipe)
+		}
+
+		tiles := s.Tiles()
+		if x < 0 || y < 0 || x+len(tiles[0]) > b.width || y+len(tiles) > b.height {
+			return nil, fmt.Errorf("structure %s outside of the blueprint", product.name)
+		}
+
+		b.entries = append(b.entries, BlueprintEntry{x, y, s})
+	}
+
+	return b, nil
+}
+
+func parseInts(text string, count int) ([]int, error) {
+	fields := strings.Split(text, ",")
+	if len(fields) != count {
+		return nil, fmt.Errorf("expected %d values, found %q", count, text)
+	}
+
+	values := make([]int, count)
+	for i, field := range fields {
+		value, err := strconv.Atoi(field)
+		if err != nil {
+			return nil, err
+		}
+		values[i] = value
+	}
+
+	return values, nil
+}
+
+// CaptureBlueprint creates a Blueprint from the Structure-s fully contained in the rectangle between two positions
+func (g *Game) CaptureBlueprint(name string, a, b position) *Blueprint {
+	y0, x0, y1, x1 := selectionBounds(a, b)
+
+	blueprint := &Blueprint{name, x1 - x0 + 1, y1 - y0 + 1, make([]BlueprintEntry, 0)}
+	seen := make(map[Structure]bool)
+
+	for i := y0; i <= y1; i++ {
+		for j := x0; j <= x1; j++ {
+			s, y, x := g.GetStructureAt(i, j)
+			if s == nil || seen[s] {
+				continue
+			}
+			seen[s] = true
+
+			tiles := s.Tiles()
+			if y < y0 || x < x0 || y+len(tiles)-1 > y1 || x+len(tiles[0])-1 > x1 {
+				continue
+			}
+
+			blueprint.entries = append(blueprint.entries, BlueprintEntry{x - x0, y - y0, s.CopyStructure()})
+		}
+	}
+
+	return blueprint
+}
+
+// selectionBounds returns the top left and bottom right corners of the rectangle between two positions
+func selectionBounds(a, b position) (int, int, int, int) {
+	y0, y1 := a.y, b.y
+	if y0 > y1 {
+		y0, y1 = y1, y0
+	}
+
+	x0, x1 := a.x, b.x
+	if x0 > x1 {
+		x0, x1 = x1, x0
+	}
+
+	return y0, x0, y1, x1
+}
+
+// CanPlaceBlueprint indicates if all the Structure-s of the Blueprint can be placed and built from the inventory
+func (g *Game) CanPlaceBlueprint(y, x int, b *Blueprint) bool {
+	for product, count := range b.Requirements() {
+		if g.inventory.objects[product] < count {
+			return false
+		}
+	}
+
+	for _, entry := range b.entries {
+		if !g.CanPlaceStructure(y+entry.y, x+entry.x, entry.structure) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// PlaceBlueprint places all the Structure-s of the Blueprint, taking them from the inventory, as a single Action
+func (g *Game) PlaceBlueprint(y, x int, b *Blueprint) bool {
+	if !g.CanPlaceBlueprint(y, x, b) {
+		return false
+	}
+
+	actions := make(groupAction, 0, len(b.entries))
+	for _, entry := range b.entries {
+		s := entry.structure.CopyStructure()
+		if a := g.buildStructure(y+entry.y, x+entry.x, s); a != nil {
+			actions = append(actions, a)
+		}
+	}
+	g.history.record(actions)
+
+	return true
+}
+
+// BlueprintLibrary the Blueprint-s saved by the player, stored one per line as "name<TAB>text"
+type BlueprintLibrary struct {
+	path       string
+	blueprints []*Blueprint
+
+	// unparsed the lines of the file that are not valid Blueprint-s, written back unchanged
+	unparsed []string
+	// readErr prevents overwriting a file that could not be read
+	readErr error
+}
+
+// defaultBlueprintLibraryPath returns the location of the library in the user configuration directory
+func defaultBlueprintLibraryPath() string {
+	dir, err := ConfigDir()
+	if err != nil {
+		dir = "."
+	}
+
+	return filepath.Join(dir, blueprintLibraryFile)
+}
+
+// LoadBlueprintLibrary reads the BlueprintLibrary from the file, a missing file is an empty library; the lines
+// that cannot be parsed are kept and the error of the first one is returned
+func LoadBlueprintLibrary(path string) (*BlueprintLibrary, error) {
+	l := &BlueprintLibrary{path, make([]*Blueprint, 0), make([]string, 0), nil}
+
+	f, err := os.Open(path)
+	if os.IsNotExist(err) {
+		return l, nil
+	}
+	if err != nil {
+		l.readErr = err
+		return l, err
+	}
+	defer f.Close()
+
+	var parseErr error
+
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	for line := 1; scanner.Scan(); line++ {
+		raw := scanner.Text()
+		text := strings.TrimSpace(raw)
+		if text == "" || strings.HasPrefix(text, "#") {
+			continue
+		}
+
+		// a line holding only the text of a shared blueprint gets a default name
+		name := fmt.Sprintf("Blueprint %d", len(l.blueprints)+1)
+		if i := strings.Index(text, "\t"); i >= 0 {
+			name, text = strings.TrimSpace(text[:i]), strings.TrimSpace(text[i+1:])
+		}
+
+		b, err := ParseBlueprint(name, text)
+		if err != nil {
+			if parseErr == nil {
+				parseErr = fmt.Errorf("%s:%d: %v", path, line, err)
+			}
+			l.unparsed = append(l.unparsed, raw)
+			continue
+		}
+
+		l.blueprints = append(l.blueprints, b)
+	}
+
+	if err := scanner.Err(); err != nil {
+		l.readErr = err
+		return l, err
+	}
+
+	return l, parseErr
+}
+
+// Blueprints returns the Blueprint-s in the BlueprintLibrary
+func (l *BlueprintLibrary) Blueprints() []*Blueprint {
+	return l.blueprints
+}
+
+// Add appends the Blueprint to the BlueprintLibrary and saves it
+func (l *BlueprintLibrary) Add(b *Blueprint) error {
+	l.blueprints = append(l.blueprints, b)
+	return l.Save()
+}
+
+// Remove deletes the Blueprint at the index from the BlueprintLibrary and saves it
+func (l *BlueprintLibrary) Remove(i int) error {
+	if i < 0 || i >= len(l.blueprints) {
+		return nil
+	}
+
+	l.blueprints = append(l.blueprints[:i], l.blueprints[i+1:]...)
+	return l.Save()
+}
+
+// Save writes the BlueprintLibrary to its file, followed by the lines that could not be parsed
+func (l *BlueprintLibrary) Save() error {
+	if l.readErr != nil {
+		return fmt.Errorf("blueprints not saved, the library could not be read: %v", l.readErr)
+	}
+
+	if err := os.MkdirAll(filepath.Dir(l.path), 0755); err != nil {
+		return err
+	}
+
+	var sb strings.Builder
+	for _, b := range l.blueprints {
+		fmt.Fprintf(&sb, "%s\t%s\n", b.name, b.String())
+	}
+	for _, line := range l.unparsed {
+		fmt.Fprintln(&sb, line)
+	}
+
+	return ioutil.WriteFile(l.path, []byte(sb.String()), 0644)
+}
@@ -0,0 +1,128 @@
+package main
+
+import (
+	"time"
+)
+
+// TicksPerMinute the number of game ticks in one minute
+const TicksPerMinute = float64(time.Minute / TickDuration)
+
+// ProductionLine the Structure-s needed to sustain the rate of one Product, in items per minute
+type ProductionLine struct {
+	product *Product
+	recipe  *Recipe
+	rate    float64
+}
+
+// Machines returns the number of Factory-s, or Extractor-s for raw resources, producing the rate
+func (l *ProductionLine) Machines() float64 {
+	if l.recipe == nil {
+		return l.rate * float64(CycleSizeExtractor) / TicksPerMinute
+	}
+
+	return l.rate * float64(l.recipe.productionTicks) / TicksPerMinute
+}
+
+// Belts returns the number of Belt lanes needed to carry the rate
+func (l *ProductionLine) Belts() float64 {
+	return l.rate * float64(CycleSizeBelt) / TicksPerMinute
+}
+
+// FluidLine the OffshorePump-s needed to sustain the rate of one Fluid, in units per minute
+type FluidLine struct {
+	fluid *Fluid
+	rate  float64
+}
+
+// Pumps returns the number of OffshorePump-s producing the rate
+func (l *FluidLine) Pumps() float64 {
+	return l.rate / (float64(PumpRate) * TicksPerMinute)
+}
+
+// ProductionPlan the expanded recipe tree of a Product produced at a target rate
+type ProductionPlan struct {
+	lines  []*ProductionLine
+	fluids []*FluidLine
+}
+
+// RecipeFor returns the Recipe producing the Product, nil for raw resources
+func RecipeFor(p *Product) *Recipe {
+	for _, recipe := range GlobalRecipeFactory.Assembly {
+		if recipe.output == p {
+			return recipe
+		}
+	}
+
+	return nil
+}
+
+// NewProductionPlan expands the recipe tree of the Product for the rate, in items per minute; intermediates
+// shared between several recipes are merged into a single ProductionLine
+func NewProductionPlan(p *Product, rate float64) *ProductionPlan {
+	plan := new(ProductionPlan)
+	plan.lines = make([]*ProductionLine, 0)
+	plan.fluids = make([]*FluidLine, 0)
+
+	plan.add(p, rate)
+
+	return plan
+}
+
+func (plan *ProductionPlan) add(p *Product, rate float64) {
+	recipe := RecipeFor(p)
+
+	line := plan.line(p)
+	if line == nil {
+		line = &ProductionLine{p, recipe, 0}
+		plan.lines = append(plan.lines, line)
+	}
+	line.rate += rate
+
+	if recipe == nil {
+		return
+	}
+
+	// each craft produces a single item
+	for _, input := range recipe.inputOrder {
+		plan.add(input, rate*float64(recipe.input[input]))
+	}
+
+	for _, f := range recipe.fluidOrder {
+		fluid := plan.fluid(f)
+		if fluid == nil {
+			fluid = &FluidLine{f, 0}
+			plan.fluids = append(plan.fluids, fluid)
+		}
+		fluid.rate += rate * float64(recipe.fluidInput[f])
+	}
+}
+
+func (plan *ProductionPlan) line(p *Product) *ProductionLine {
+	for _, line := range plan.lines {
+		if line.product == p {
+			return line
+		}
+	}
+
+	return nil
+}
+
+func (plan *ProductionPlan) fluid(f *Fluid) *FluidLine {
+	for _, line := range plan.fluids {
+		if line.fluid == f {
+			return line
+		}
+	}
+
+	return nil
+}
+
+// Lines returns the ProductionLine-s of the plan, the target Product first
+func (plan *ProductionPlan) Lines() []*ProductionLine {
+	return plan.lines
+}
+
+// Fluids returns the FluidLine-s of the plan
+func (plan *ProductionPlan) Fluids() []*FluidLine {
+	return plan.fluids
+}
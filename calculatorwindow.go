@@ -0,0 +1,199 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/jroimartin/gocui"
+)
+
+// CalculatorDefaultRate the initial target rate of the calculator, in items per minute
+const CalculatorDefaultRate int = 60
+
+// CalculatorWindow a Window that computes the Structure-s needed for a production rate
+type CalculatorWindow struct {
+	manager WindowManager
+	widgets []Widget
+}
+
+// NewCalculatorWindow creates a new CalculatorWindow
+func NewCalculatorWindow(manager WindowManager) *CalculatorWindow {
+	var w CalculatorWindow
+	w.manager = manager
+
+	menuWidget := &CalculatorMenuWidget{"CalculatorMenu", 0, CalculatorDefaultRate}
+	planWidget := &CalculatorPlanWidget{"CalculatorPlan", menuWidget}
+
+	w.widgets = append(w.widgets, menuWidget)
+	w.widgets = append(w.widgets, planWidget)
+
+	return &w
+}
+
+// Layout displays the CalculatorWindow
+func (w *CalculatorWindow) Layout(g *gocui.Gui) error {
+	maxX, maxY := g.Size()
+
+	g.Cursor = false
+
+	v, err := g.SetView("CalculatorWindow", 0, 0, maxX-1, maxY-1)
+	if err == nil || err == gocui.ErrUnknownView {
+		if _, err := g.SetViewOnTop("CalculatorWindow"); err != nil {
+			return err
+		}
+
+		v.Title = "Ratio calculator"
+	}
+
+	for _, widget := range w.widgets {
+		widget.Layout(g)
+	}
+
+	return nil
+}
+
+// CalculatorMenuWidget allows the selection of the target Product and rate
+type CalculatorMenuWidget struct {
+	name string
+	sel  int
+	rate int
+}
+
+// Product returns the selected Product
+func (w *CalculatorMenuWidget) Product() *Product {
+	return GlobalProductFactory.cannonicalOrder[w.sel]
+}
+
+// Layout displays the CalculatorMenuWidget
+func (w *CalculatorMenuWidget) Layout(g *gocui.Gui) error {
+	_, maxY := g.Size()
+
+	v, err := g.SetView(w.name, 1, 1, 20, maxY-2)
+	if err != nil && err != gocui.ErrUnknownView {
+		return err
+	}
+
+	if err == gocui.ErrUnknownView {
+		if err := w.initBindings(g); err != nil {
+			return err
+		}
+	}
+
+	if _, err := g.SetCurrentView(w.name); err != nil {
+		return err
+	}
+
+	if _, err := g.SetViewOnTop(w.name); err != nil {
+		return err
+	}
+
+	v.Title = "Target"
+	v.Clear()
+
+	fmt.Fprintf(v, "rate: %d/min\n\n", w.rate)
+	for i, product := range GlobalProductFactory.cannonicalOrder {
+		prefix := ' '
+		if w.sel == i {
+			prefix = '>'
+		}
+
+		fmt.Fprintf(v, "%c %s\n", prefix, product.name)
+	}
+
+	return nil
+}
+
+func (w *CalculatorMenuWidget) initBindings(g *gocui.Gui) error {
+	if err := g.SetKeybinding(w.name, gocui.KeyArrowUp, gocui.ModNone,
+		w.move(-1)); err != nil {
+		return err
+	}
+	if err := g.SetKeybinding(w.name, gocui.KeyArrowDown, gocui.ModNone,
+		w.move(1)); err != nil {
+		return err
+	}
+	if err := g.SetKeybinding(w.name, gocui.KeyArrowLeft, gocui.ModNone,
+		w.change(-1)); err != nil {
+		return err
+	}
+	if err := g.SetKeybinding(w.name, gocui.KeyArrowRight, gocui.ModNone,
+		w.change(1)); err != nil {
+		return err
+	}
+	if err := GlobalKeymap.SetKeybinding(g, w.name, CommandDecrease,
+		w.change(-10)); err != nil {
+		return err
+	}
+	if err := GlobalKeymap.SetKeybinding(g, w.name, CommandIncrease,
+		w.change(10)); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (w *CalculatorMenuWidget) move(d int) func(g *gocui.Gui, v *gocui.View) error {
+	return func(g *gocui.Gui, v *gocui.View) error {
+		newSel := w.sel + d
+		if newSel >= 0 && newSel < len(GlobalProductFactory.cannonicalOrder) {
+			w.sel = newSel
+		}
+
+		return nil
+	}
+}
+
+func (w *CalculatorMenuWidget) change(d int) func(g *gocui.Gui, v *gocui.View) error {
+	return func(g *gocui.Gui, v *gocui.View) error {
+		w.rate += d
+		if w.rate < 1 {
+			w.rate = 1
+		}
+
+		return nil
+	}
+}
+
+// CalculatorPlanWidget displays the ProductionPlan for the Product and rate of the CalculatorMenuWidget
+type CalculatorPlanWidget struct {
+	name string
+	menu *CalculatorMenuWidget
+}
+
+// Layout displays the CalculatorPlanWidget
+func (w *CalculatorPlanWidget) Layout(g *gocui.Gui) error {
+	maxX, maxY := g.Size()
+
+	v, err := g.SetView(w.name, 21, 1, maxX-2, maxY-2)
+	if err != nil && err != gocui.ErrUnknownView {
+		return err
+	}
+
+	if _, err := g.SetViewOnTop(w.name); err != nil {
+		return err
+	}
+
+	v.Title = "Production"
+	v.Clear()
+
+	plan := NewProductionPlan(w.menu.Product(), float64(w.menu.rate))
+
+	fmt.Fprintf(v, "%-12s %9s %9s %6s\n", "product", "rate/min", "machines", "belts")
+	for _, line := range plan.Lines() {
+		machine := "factory"
+		if line.recipe == nil {
+			machine = "extractor"
+		}
+
+		fmt.Fprintf(v, "%-12s %9.1f %9.2f %6.2f %s\n", line.product.name, line.rate, line.Machines(), line.Belts(), machine)
+	}
+
+	for _, line := range plan.Fluids() {
+		fmt.Fprintf(v, "%-12s %9.1f %9.2f %6s pump\n", line.fluid.name, line.rate, line.Pumps(), "-")
+	}
+
+	fmt.Fprint(v, "\nproduct: ↑↓\n")
+	fmt.Fprintf(v, "rate   : ←→ %s %s\n", GlobalKeymap.Help(CommandDecrease), GlobalKeymap.Help(CommandIncrease))
+	fmt.Fprint(v, "back   : ⌫\n")
+
+	return nil
+}
@@ -0,0 +1,448 @@
+package main
+
+import (
+	"fmt"
+)
+
+// Signals the values published on a CircuitNetwork, indexed by Product
+type Signals map[*Product]int
+
+const (
+	comparatorLess int = iota
+	comparatorGreater
+	comparatorEqual
+	comparatorNotEqual
+	comparatorLessEqual
+	comparatorGreaterEqual
+)
+
+// comparatorSymbols the representation of the comparators used by a CircuitCondition
+var comparatorSymbols = []string{"<", ">", "=", "!=", "<=", ">="}
+
+const (
+	operationAdd int = iota
+	operationSubtract
+	operationMultiply
+	operationDivide
+)
+
+// operationSymbols the representation of the operations used by an ArithmeticCombinator
+var operationSymbols = []string{"+", "-", "*", "/"}
+
+// signalName returns the name of the Product used as signal, nil meaning no signal
+func signalName(p *Product) string {
+	if p == nil {
+		return "none"
+	}
+
+	return p.name
+}
+
+// CircuitCondition compares a signal of the CircuitNetwork with a constant
+type CircuitCondition struct {
+	signal     *Product
+	comparator int
+	constant   int
+}
+
+// NewCircuitCondition creates a new *CircuitCondition, "none >= 0" holds until it is edited
+func NewCircuitCondition() *CircuitCondition {
+	c := new(CircuitCondition)
+	c.comparator = comparatorGreaterEqual
+
+	return c
+}
+
+// Evaluate indicates if the CircuitCondition holds for the Signals
+func (c *CircuitCondition) Evaluate(signals Signals) bool {
+	value := signals[c.signal]
+
+	switch c.comparator {
+	case comparatorLess:
+		return value < c.constant
+	case comparatorGreater:
+		return value > c.constant
+	case comparatorEqual:
+		return value == c.constant
+	case comparatorNotEqual:
+		return value != c.constant
+	case comparatorLessEqual:
+		return value <= c.constant
+	case comparatorGreaterEqual:
+		return value >= c.constant
+	}
+
+	return false
+}
+
+// String returns a human readable representation of the CircuitCondition
+func (c *CircuitCondition) String() string {
+	return fmt.Sprintf("%s %s %d", signalName(c.signal), comparatorSymbols[c.comparator], c.constant)
+}
+
+// Combinator a Structure that publishes Signals computed from its CircuitNetwork
+type Combinator interface {
+	Structure
+	Compute(Signals)
+	Output() Signals
+}
+
+// ArithmeticCombinatorTile is the map representation of an arithmetic combinator
+type ArithmeticCombinatorTile struct {
+	BaseStructureTile
+}
+
+// NewArithmeticCombinatorTile creates a new *ArithmeticCombinatorTile
+func NewArithmeticCombinatorTile() *ArithmeticCombinatorTile {
+	return &ArithmeticCombinatorTile{BaseStructureTile{0, 1, "arithmetic", nil, nil, nil}}
+}
+
+// DeciderCombinatorTile is the map representation of a decider combinator
+type DeciderCombinatorTile struct {
+	BaseStructureTile
+}
+
+// NewDeciderCombinatorTile creates a new *DeciderCombinatorTile
+func NewDeciderCombinatorTile() *DeciderCombinatorTile {
+	return &DeciderCombinatorTile{BaseStructureTile{0, 1, "decider", nil, nil, nil}}
+}
+
+// BaseCombinator the common part of the combinators, they do not handle Product-s
+type BaseCombinator struct {
+	BaseStructure
+	output Signals
+}
+
+// Output returns the Signals published by the combinator during the last tick
+func (c *BaseCombinator) Output() Signals {
+	return c.output
+}
+
+// Tick does nothing for BaseCombinator, signals are handled by the Game
+func (c *BaseCombinator) Tick() {
+}
+
+// CanRetrieveProduct does nothing for BaseCombinator, it does not handle Product-s
+func (c *BaseCombinator) CanRetrieveProduct() (*Product, bool) {
+	return nil, false
+}
+
+// RetrieveProduct does nothing for BaseCombinator, it does not handle Product-s
+func (c *BaseCombinator) RetrieveProduct() (*Product, bool) {
+	return nil, false
+}
+
+// CanAcceptProduct does nothing for BaseCombinator, it does not handle Product-s
+func (c *BaseCombinator) CanAcceptProduct(*Product) bool {
+	return false
+}
+
+// AcceptProduct does nothing for BaseCombinator, it does not handle Product-s
+func (c *BaseCombinator) AcceptProduct(*Product) bool {
+	return false
+}
+
+// RotateRight does nothing for a BaseCombinator
+func (c *BaseCombinator) RotateRight() {
+}
+
+// RotateLeft does nothing for a BaseCombinator
+func (c *BaseCombinator) RotateLeft() {
+}
+
+// ArithmeticCombinator publishes the result of an operation between a signal and a constant
+type ArithmeticCombinator struct {
+	BaseCombinator
+	signal    *Product
+	operation int
+	constant  int
+	result    *Product
+}
+
+// NewArithmeticCombinator creates a new *ArithmeticCombinator
+func NewArithmeticCombinator() *ArithmeticCombinator {
+	block := new(ArithmeticCombinator)
+	block.tiles = [][]StructureTile{
+		{NewArithmeticCombinatorTile()},
+	}
+
+	block.inputs = make([]Transfer, 0)
+	block.outputs = make([]Transfer, 0)
+
+	block.output = make(Signals)
+
+	return block
+}
+
+// CopyStructure creates a copy of the ArithmeticCombinator
+func (c *ArithmeticCombinator) CopyStructure() Structure {
+	combinator := new(ArithmeticCombinator)
+	combinator.BaseStructure = *c.BaseStructure.copyStructure(combinator)
+	combinator.output = make(Signals)
+	combinator.signal = c.signal
+	combinator.operation = c.operation
+	combinator.constant = c.constant
+	combinator.result = c.result
+
+	return combinator
+}
+
+// Compute calculates the Signals to be published on the next tick
+func (c *ArithmeticCombinator) Compute(signals Signals) {
+	value := signals[c.signal]
+
+	switch c.operation {
+	case operationAdd:
+		value += c.constant
+	case operationSubtract:
+		value -= c.constant
+	case operationMultiply:
+		value *= c.constant
+	case operationDivide:
+		if c.constant == 0 {
+			value = 0
+		} else {
+			value /= c.constant
+		}
+	}
+
+	c.output = make(Signals)
+	if value != 0 && c.result != nil {
+		c.output[c.result] = value
+	}
+}
+
+// String returns a human readable representation of the ArithmeticCombinator
+func (c *ArithmeticCombinator) String() string {
+	return fmt.Sprintf("%s %s %d", signalName(c.signal), operationSymbols[c.operation], c.constant)
+}
+
+// GetCode return the code for the ArithmeticCombinator type
+func (*ArithmeticCombinator) GetCode() int {
+	return ProductStructureArithmeticCombinator
+}
+
+// DeciderCombinator publishes a signal of 1 when its CircuitCondition holds
+type DeciderCombinator struct {
+	BaseCombinator
+	condition *CircuitCondition
+	result    *Product
+}
+
+// NewDeciderCombinator creates a new *DeciderCombinator
+func NewDeciderCombinator() *DeciderCombinator {
+	block := new(DeciderCombinator)
+	block.tiles = [][]StructureTile{
+		{NewDeciderCombinatorTile()},
+	}
+
+	block.inputs = make([]Transfer, 0)
+	block.outputs = make([]Transfer, 0)
+
+	block.output = make(Signals)
+	block.condition = NewCircuitCondition()
+
+	return block
+}
+
+// CopyStructure creates a copy of the DeciderCombinator
+func (c *DeciderCombinator) CopyStructure() Structure {
+	combinator := new(DeciderCombinator)
+	combinator.BaseStructure = *c.BaseStructure.copyStructure(combinator)
+	combinator.output = make(Signals)
+	condition := *c.condition
+	combinator.condition = &condition
+	combinator.result = c.result
+
+	return combinator
+}
+
+// Compute calculates the Signals to be published on the next tick
+func (c *DeciderCombinator) Compute(signals Signals) {
+	c.output = make(Signals)
+	if c.condition.Evaluate(signals) && c.result != nil {
+		c.output[c.result] = 1
+	}
+}
+
+// GetCode return the code for the DeciderCombinator type
+func (*DeciderCombinator) GetCode() int {
+	return ProductStructureDeciderCombinator
+}
+
+// CircuitNetwork a group of Structure-s connected by signal wires
+type CircuitNetwork struct {
+	members []Structure
+	signals Signals
+}
+
+// Signals returns the values published on the CircuitNetwork during the last tick
+func (n *CircuitNetwork) Signals() Signals {
+	return n.signals
+}
+
+// Connected indicates if the two Structure-s are connected by a signal wire
+func (g *Game) Connected(a, b Structure) bool {
+	return g.wires[a][b]
+}
+
+// ToggleWire connects two Structure-s with a signal wire, or removes the existing wire
+func (g *Game) ToggleWire(a, b Structure) bool {
+	if a == b {
+		return false
+	}
+
+	wire := GlobalProductFactory.GetProduct(ProductProcessedCopperWire)
+
+	if g.Connected(a, b) {
+		// the wire goes back to the inventory, it stays if it does not fit
+		if g.inventory.Add(wire, 1) != 1 {
+			return false
+		}
+
+		delete(g.wires[a], b)
+		delete(g.wires[b], a)
+	} else {
+		if g.inventory.Remove(wire, 1) != 1 {
+			return false
+		}
+
+		if g.wires[a] == nil {
+			g.wires[a] = make(map[Structure]bool)
+		}
+		if g.wires[b] == nil {
+			g.wires[b] = make(map[Structure]bool)
+		}
+
+		g.wires[a][b] = true
+		g.wires[b][a] = true
+	}
+
+	g.circuitsDirty = true
+
+	return true
+}
+
+// CircuitCondition returns the CircuitCondition that enables the Structure, if any
+func (g *Game) CircuitCondition(s Structure) *CircuitCondition {
+	return g.conditions[s]
+}
+
+// SetCircuitCondition specifies the CircuitCondition that enables the Structure, nil removes it
+func (g *Game) SetCircuitCondition(s Structure, c *CircuitCondition) {
+	if c == nil {
+		delete(g.conditions, s)
+		return
+	}
+
+	g.conditions[s] = c
+}
+
+// GetNetwork returns the CircuitNetwork the Structure is part of
+func (g *Game) GetNetwork(s Structure) *CircuitNetwork {
+	return g.networkOf[s]
+}
+
+// circuitEnabled indicates if the Structure is allowed to work by its CircuitCondition
+func (g *Game) circuitEnabled(s Structure) bool {
+	condition, present := g.conditions[s]
+	if !present {
+		return true
+	}
+
+	// a Structure without signal wires ignores its CircuitCondition
+	network := g.networkOf[s]
+	if network == nil {
+		return true
+	}
+
+	return condition.Evaluate(network.signals)
+}
+
+// freeForRemoval returns the free space of the inventory once the signal wires of the Structure are returned to it
+func (g *Game) freeForRemoval(s Structure) int {
+	return g.inventory.Capacity() - g.inventory.Size() - len(g.wires[s])
+}
+
+// removeFromCircuits disconnects the Structure from all the signal wires, returning them to the inventory; the
+// callers reserve the space with freeForRemoval
+func (g *Game) removeFromCircuits(s Structure) {
+	wire := GlobalProductFactory.GetProduct(ProductProcessedCopperWire)
+	for other := range g.wires[s] {
+		delete(g.wires[other], s)
+		g.inventory.Add(wire, 1)
+	}
+
+	delete(g.wires, s)
+	delete(g.conditions, s)
+	g.circuitsDirty = true
+}
+
+// tickCircuits publishes the signals of every CircuitNetwork
+func (g *Game) tickCircuits() {
+	if g.circuitsDirty {
+		g.buildNetworks()
+	}
+
+	for _, network := range g.networks {
+		signals := make(Signals)
+
+		for _, member := range network.members {
+			switch s := member.(type) {
+			case *Chest:
+				for product, count := range s.s.objects {
+					signals[product] += count
+				}
+			case Combinator:
+				for product, count := range s.Output() {
+					signals[product] += count
+				}
+			}
+		}
+
+		network.signals = signals
+	}
+
+	for _, network := range g.networks {
+		for _, member := range network.members {
+			if c, ok := member.(Combinator); ok {
+				c.Compute(network.signals)
+			}
+		}
+	}
+}
+
+// buildNetworks groups the wired Structure-s into CircuitNetwork-s
+func (g *Game) buildNetworks() {
+	g.networks = make([]*CircuitNetwork, 0)
+	g.networkOf = make(map[Structure]*CircuitNetwork)
+
+	for s, wires := range g.wires {
+		if _, visited := g.networkOf[s]; visited || len(wires) == 0 {
+			continue
+		}
+
+		network := &CircuitNetwork{make([]Structure, 0), make(Signals)}
+		queue := []Structure{s}
+		g.networkOf[s] = network
+
+		for len(queue) != 0 {
+			crt := queue[0]
+			queue = queue[1:]
+			network.members = append(network.members, crt)
+
+			for other := range g.wires[crt] {
+				if _, visited := g.networkOf[other]; visited {
+					continue
+				}
+
+				g.networkOf[other] = network
+				queue = append(queue, other)
+			}
+		}
+
+		g.networks = append(g.networks, network)
+	}
+
+	g.circuitsDirty = false
+}
@@ -0,0 +1,246 @@
+package main
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// ColorMode the colors the terminal can display
+type ColorMode int
+
+const (
+	// ColorMode8 the 8 ANSI colors
+	ColorMode8 ColorMode = iota
+	// ColorMode256 the 256 colors palette, also used by truecolor terminals since gocui stops at 256 colors
+	ColorMode256
+)
+
+// String returns the name of the ColorMode
+func (m ColorMode) String() string {
+	switch m {
+	case ColorMode256:
+		return "256 colors"
+	}
+
+	return "8 colors"
+}
+
+// DetectColorMode guesses the colors of the terminal from the COLORTERM and TERM environment variables, RGB
+// colors are drawn with the closest color of the palette
+func DetectColorMode(colorterm, term string) ColorMode {
+	if colorterm == "truecolor" || colorterm == "24bit" || strings.HasSuffix(term, "-direct") ||
+		strings.Contains(term, "256color") {
+		return ColorMode256
+	}
+
+	return ColorMode8
+}
+
+// ColorKind indicates how the value of a Color is interpreted
+type ColorKind int
+
+const (
+	// ColorKindANSI an SGR foreground code, from 30 to 37
+	ColorKindANSI ColorKind = iota
+	// ColorKindPalette an index of the 256 colors palette
+	ColorKindPalette
+	// ColorKindRGB a 0xRRGGBB value
+	ColorKindRGB
+)
+
+// Color a foreground color, degraded to what the terminal displays
+type Color struct {
+	Kind  ColorKind
+	Value int
+}
+
+// ANSIColor creates a Color from an SGR foreground code
+func ANSIColor(code int) Color {
+	return Color{ColorKindANSI, code}
+}
+
+// PaletteColor creates a Color from an index of the 256 colors palette
+func PaletteColor(index int) Color {
+	return Color{ColorKindPalette, index}
+}
+
+// RGBColor creates a Color from its red, green and blue components
+func RGBColor(r, g, b int) Color {
+	return Color{ColorKindRGB, r<<16 | g<<8 | b}
+}
+
+// ansiRGB the usual RGB values of the 8 ANSI colors, from black to white
+var ansiRGB = [][3]int{
+	{0, 0, 0},
+	{205, 0, 0},
+	{0, 205, 0},
+	{205, 205, 0},
+	{0, 0, 238},
+	{205, 0, 205},
+	{0, 205, 205},
+	{229, 229, 229},
+}
+
+// cubeLevels the component values of the 6x6x6 color cube of the palette
+var cubeLevels = []int{0, 95, 135, 175, 215, 255}
+
+// ParseColor parses "31" as an ANSI color, "@208" as a palette index and "#ff8700" as an RGB value
+func ParseColor(text string) (Color, error) {
+	switch {
+	case strings.HasPrefix(text, "@"):
+		index, err := strconv.Atoi(text[1:])
+		if err != nil || index < 0 || index > 255 {
+			return Color{}, fmt.Errorf("invalid palette color %q", text)
+		}
+
+		return PaletteColor(index), nil
+	case strings.HasPrefix(text, "#"):
+		value, err := strconv.ParseUint(text[1:], 16, 32)
+		if err != nil || len(text) != 7 {
+			return Color{}, fmt.Errorf("invalid RGB color %q", text)
+		}
+
+		return Color{ColorKindRGB, int(value)}, nil
+	}
+
+	code, err := strconv.Atoi(text)
+	if err != nil || code < 30 || code > 37 {
+		return Color{}, fmt.Errorf("invalid color %q", text)
+	}
+
+	return ANSIColor(code), nil
+}
+
+// RGB returns the red, green and blue components of the Color
+func (c Color) RGB() (int, int, int) {
+	switch c.Kind {
+	case ColorKindANSI:
+		rgb := ansiRGB[c.Value-30]
+		return rgb[0], rgb[1], rgb[2]
+	case ColorKindPalette:
+		switch {
+		case c.Value < 8:
+			rgb := ansiRGB[c.Value]
+			return rgb[0], rgb[1], rgb[2]
+		case c.Value < 16:
+			// the bright variants of the ANSI colors
+			rgb := ansiRGB[c.Value-8]
+			return (rgb[0] + 255) / 2, (rgb[1] + 255) / 2, (rgb[2] + 255) / 2
+		case c.Value < 232:
+			i := c.Value - 16
+			return cubeLevels[i/36], cubeLevels[i/6%6], cubeLevels[i%6]
+		}
+
+		gray := 8 + (c.Value-232)*10
+		return gray, gray, gray
+	}
+
+	return c.Value >> 16 & 0xff, c.Value >> 8 & 0xff, c.Value & 0xff
+}
+
+// Blend returns the Color at the fraction t of the way from c to other
+func (c Color) Blend(other Color, t float64) Color {
+	t = math.Max(0, math.Min(1, t))
+
+	r0, g0, b0 := c.RGB()
+	r1, g1, b1 := other.RGB()
+	mix := func(a, b int) int {
+		return a + int(math.Round(float64(b-a)*t))
+	}
+
+	return RGBColor(mix(r0, r1), mix(g0, g1), mix(b0, b1))
+}
+
+// SGR returns the parameters of the escape sequence selecting the Color as foreground, falling back to the
+// closest color the ColorMode displays
+func (c Color) SGR(mode ColorMode) string {
+	if c.Kind == ColorKindANSI {
+		return strconv.Itoa(c.Value)
+	}
+
+	r, g, b := c.RGB()
+	if mode == ColorMode8 {
+		return strconv.Itoa(30 + closestColor(r, g, b, ansiRGB))
+	}
+
+	if c.Kind == ColorKindPalette {
+		return fmt.Sprintf("38;5;%d", c.Value)
+	}
+
+	return fmt.Sprintf("38;5;%d", paletteIndex(r, g, b))
+}
+
+// paletteIndex returns the index of the color of the cube or of the gray ramp closest to the RGB value
+func paletteIndex(r, g, b int) int {
+	level := func(v int) int {
+		best := 0
+		for i, l := range cubeLevels {
+			if math.Abs(float64(v-l)) < math.Abs(float64(v-cubeLevels[best])) {
+				best = i
+			}
+		}
+
+		return best
+	}
+
+	cr, cg, cb := level(r), level(g), level(b)
+	cube := [3]int{cubeLevels[cr], cubeLevels[cg], cubeLevels[cb]}
+
+	gray := (r + g + b) / 3
+	grayIndex := 23
+	if gray < 238 {
+		grayIndex = int(math.Max(0, math.Round(float64(gray-8)/10)))
+	}
+	grayValue := 8 + grayIndex*10
+
+	if closestColor(r, g, b, [][3]int{cube, {grayValue, grayValue, grayValue}}) == 1 {
+		return 232 + grayIndex
+	}
+
+	return 16 + cr*36 + cg*6 + cb
+}
+
+// closestColor returns the index of the color of the list closest to the RGB value
+func closestColor(r, g, b int, colors [][3]int) int {
+	best, bestDistance := 0, math.MaxInt32
+	for i, c := range colors {
+		dr, dg, db := r-c[0], g-c[1], b-c[2]
+		if d := dr*dr + dg*dg + db*db; d < bestDistance {
+			best, bestDistance = i, d
+		}
+	}
+
+	return best
+}
+
+// hueColor returns the i-th of a series of colors of distinct hues, spread by the golden angle so that neighbours
+// differ the most
+func hueColor(i int) Color {
+	h := math.Mod(float64(i)*137.508, 360) / 60
+	x := 1 - math.Abs(math.Mod(h, 2)-1)
+
+	var r, g, b float64
+	switch int(h) {
+	case 0:
+		r, g = 1, x
+	case 1:
+		r, g = x, 1
+	case 2:
+		g, b = 1, x
+	case 3:
+		g, b = x, 1
+	case 4:
+		r, b = x, 1
+	default:
+		r, b = 1, x
+	}
+
+	// keep the colors light enough to read on a dark background
+	scale := func(v float64) int {
+		return int(math.Round(255 * (0.35 + 0.65*v)))
+	}
+
+	return RGBColor(scale(r), scale(g), scale(b))
+}
@@ -0,0 +1,188 @@
+package main
+
+const (
+	// OverflowStop stops the deconstruction at the first Structure that does not fit in the inventory
+	OverflowStop int = iota
+	// OverflowDiscard keeps deconstructing, discarding the carried Product-s that do not fit in the inventory
+	OverflowDiscard
+	overflowCount
+)
+
+// overflowNames the human readable names of the overflow policies
+var overflowNames = []string{"stop", "discard"}
+
+// structureContents returns the Product-s carried or stored by the Structure
+func structureContents(s Structure) map[*Product]int {
+	contents := make(map[*Product]int)
+
+	switch ss := s.(type) {
+	case *Chest:
+		for p, c := range ss.s.objects {
+			contents[p] += c
+		}
+	case *Belt:
+		if ss.Product != nil {
+			contents[ss.Product]++
+		}
+	case *Extractor:
+		if ss.product != nil {
+			contents[ss.product]++
+		}
+	case *Splitter:
+		for _, progress := range ss.products {
+			contents[progress.p]++
+		}
+	case *Underground:
+		for _, progress := range ss.products {
+			contents[progress.p]++
+		}
+	case *Factory:
+		for p, c := range ss.inProducts {
+			contents[p] += c
+		}
+		if ss.recipe != nil && ss.counter == ss.recipe.productionTicks {
+			contents[ss.recipe.output]++
+		}
+	}
+
+	return contents
+}
+
+// clearContents empties the Structure of the Product-s returned by structureContents
+func clearContents(s Structure) {
+	switch ss := s.(type) {
+	case *Chest:
+		for p, c := range structureContents(ss) {
+			ss.s.Remove(p, c)
+		}
+	case *Belt:
+		ss.Product = nil
+		ss.Counter = 0
+		switch t := ss.tiles[0][0].(type) {
+		case *BaseStructureTile:
+			t.SetProduct(nil)
+		}
+	case *Extractor:
+		ss.product = nil
+		ss.counter = 0
+	case *Splitter:
+		ss.products = make([]ProductProgress, 0)
+	case *Underground:
+		ss.products = make([]ProductProgress, 0)
+	case *Factory:
+		ss.inProducts = make(map[*Product]int)
+		if ss.recipe != nil && ss.counter == ss.recipe.productionTicks {
+			ss.counter = 0
+		}
+	}
+}
+
+// restoreContents puts back in the Structure the Product-s removed by clearContents
+func restoreContents(s Structure, contents map[*Product]int) {
+	for _, p := range GlobalProductFactory.cannonicalOrder {
+		c := contents[p]
+		if c == 0 {
+			continue
+		}
+
+		switch ss := s.(type) {
+		case *Chest:
+			ss.s.Add(p, c)
+		case *Belt:
+			ss.AcceptProduct(p)
+		case *Extractor:
+			ss.product = p
+		case *Splitter:
+			for i := 0; i < c; i++ {
+				ss.AcceptProduct(p)
+			}
+		case *Underground:
+			for i := 0; i < c; i++ {
+				ss.AcceptProduct(p)
+			}
+		case *Factory:
+			// the finished Product is the recipe output that is not one of its inputs
+			if ss.recipe != nil && p == ss.recipe.output && ss.recipe.input[p] == 0 {
+				ss.counter = ss.recipe.productionTicks
+				c--
+			}
+			if c > 0 {
+				ss.inProducts[p] += c
+			}
+		}
+	}
+}
+
+// areaStructures returns the Structure-s covering the rectangle between two positions and their top left corners
+func (g *Game) areaStructures(a, b position) ([]Structure, []position) {
+	y0, x0, y1, x1 := selectionBounds(a, b)
+
+	structures := make([]Structure, 0)
+	positions := make([]position, 0)
+	seen := make(map[Structure]bool)
+
+	for i := y0; i <= y1; i++ {
+		for j := x0; j <= x1; j++ {
+			s, y, x := g.GetStructureAt(i, j)
+			if s == nil || seen[s] {
+				continue
+			}
+			seen[s] = true
+
+			structures = append(structures, s)
+			positions = append(positions, position{x: x, y: y})
+		}
+	}
+
+	return structures, positions
+}
+
+// DeconstructionSummary returns the number of Structure-s in the rectangle and of the Product-s they carry
+func (g *Game) DeconstructionSummary(a, b position) (int, int) {
+	structures, _ := g.areaStructures(a, b)
+
+	products := 0
+	for _, s := range structures {
+		for _, c := range structureContents(s) {
+			products += c
+		}
+	}
+
+	return len(structures), products
+}
+
+// DeconstructArea removes all the Structure-s covering the rectangle, returning them and their contents
+// to the inventory as a single Action, it returns the number of removed Structure-s and discarded Product-s
+func (g *Game) DeconstructArea(a, b position, overflow int) (int, int) {
+	structures, positions := g.areaStructures(a, b)
+
+	actions := make(groupAction, 0, len(structures))
+	discarded := 0
+
+	for i, s := range structures {
+		if g.underTrain(s, positions[i].y, positions[i].x) {
+			// the rails under a Train stay, the rest of the area is still cleared
+			continue
+		}
+
+		wires := make([]Structure, 0, len(g.wires[s]))
+		for other := range g.wires[s] {
+			wires = append(wires, other)
+		}
+
+		action := &removeAction{positions[i].y, positions[i].x, s, wires, g.conditions[s], overflow == OverflowDiscard,
+			nil, 0}
+		if !action.Redo(g) {
+			break
+		}
+
+		discarded += action.discarded
+		actions = append(actions, action)
+	}
+
+	if len(actions) != 0 {
+		g.history.record(actions)
+	}
+
+	return len(actions), discarded
+}
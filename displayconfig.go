@@ -1,10 +1,31 @@
 package main
 
+import (
+	"fmt"
+	"time"
+	"unicode/utf8"
+)
+
+const (
+	// CueBlinkPeriod the time the blinking cues show the mark, then the glyph
+	CueBlinkPeriod = 400 * time.Millisecond
+
+	// SymbolSetFreeMono the name of the built-in unicode SymbolConfig
+	SymbolSetFreeMono = "FreeMono"
+	// SymbolSetASCII the name of the built-in ascii SymbolConfig
+	SymbolSetASCII = "ascii"
+)
+
 // GlobalDisplayConfigManager contains the global display configuration
 var GlobalDisplayConfigManager = newDisplayConfigManager()
 
 // DisplayConfigManager stores information about color and symbol configurations
 type DisplayConfigManager struct {
+	// ColorMode the colors of the terminal, the colors of the configurations fall back to it
+	ColorMode ColorMode
+	// Cues adds marks that do not rely on colors to the invalid ghosts and to the selection
+	Cues bool
+
 	ColorConfigs  []*ColorConfig
 	sColorConfig  *ColorConfig
 	SymbolConfigs []*SymbolConfig
@@ -16,18 +37,88 @@ func newDisplayConfigManager() *DisplayConfigManager {
 
 	eightColorConfig := new(ColorConfig)
 	eightColorConfig.Name = "8 color"
-	eightColorConfig.StructureColors = [][]int{
-		{36, 1},
-		{37, 1},
-		{36, 1},
-		{31, 1},
+	eightColorConfig.StructureColors = []StructureColor{
+		{ANSIColor(36), 1},
+		{ANSIColor(37), 1},
+		{ANSIColor(36), 1},
+		{ANSIColor(31), 1},
+		{ANSIColor(32), 1},
+		{ANSIColor(33), 1},
+		{ANSIColor(31), 7},
+		{ANSIColor(34), 1},
+	}
+	eightColorConfig.ResourceColors = []Color{ANSIColor(33), ANSIColor(31), ANSIColor(32), ANSIColor(34)}
+
+	// falls back to the 8 colors on terminals without a palette
+	paletteColorConfig := new(ColorConfig)
+	paletteColorConfig.Name = "256 color"
+	paletteColorConfig.StructureColors = []StructureColor{
+		{PaletteColor(80), 1},
+		{PaletteColor(231), 1},
+		{PaletteColor(80), 1},
+		{PaletteColor(196), 1},
+		{PaletteColor(46), 1},
+		{PaletteColor(220), 1},
+		{PaletteColor(196), 7},
+		{PaletteColor(75), 1},
 	}
-	eightColorConfig.ResourceColors = []int{33, 31, 32}
+	paletteColorConfig.ResourceColors = []Color{PaletteColor(208), PaletteColor(110), PaletteColor(180), PaletteColor(33)}
+	paletteColorConfig.ResourceGradients = [][]Color{
+		{RGBColor(0x5f, 0x2f, 0x00), RGBColor(0xff, 0x87, 0x00)},
+		{RGBColor(0x2f, 0x3f, 0x5f), RGBColor(0xaf, 0xd7, 0xff)},
+		{RGBColor(0x4f, 0x3f, 0x2f), RGBColor(0xd7, 0xaf, 0x87)},
+		nil,
+	}
+	paletteColorConfig.ProductColors = GlobalProductFactory.Colors()
+
+	// the Okabe-Ito colors, told apart with any of the three color vision deficiencies
+	orange, skyBlue, bluishGreen := RGBColor(0xe6, 0x9f, 0x00), RGBColor(0x56, 0xb4, 0xe9), RGBColor(0x00, 0x9e, 0x73)
+	yellow, blue, vermillion := RGBColor(0xf0, 0xe4, 0x42), RGBColor(0x00, 0x72, 0xb2), RGBColor(0xd5, 0x5e, 0x00)
+	purple, gray, white := RGBColor(0xcc, 0x79, 0xa7), RGBColor(0x99, 0x99, 0x99), RGBColor(0xff, 0xff, 0xff)
+	okabeIto := []Color{orange, skyBlue, bluishGreen, yellow, blue, vermillion, purple}
+
+	// red and green look alike, blue and orange do not
+	deuteranopiaColorConfig := newColorblindConfig("deuteranopia",
+		[]Color{skyBlue, white, skyBlue, orange, skyBlue, yellow, vermillion, gray},
+		[]Color{orange, skyBlue, RGBColor(0xc0, 0xc0, 0xc0), blue},
+		[][]Color{
+			{RGBColor(0x5a, 0x3e, 0x00), orange},
+			{RGBColor(0x1f, 0x4b, 0x66), skyBlue},
+			{RGBColor(0x4a, 0x4a, 0x4a), RGBColor(0xd0, 0xd0, 0xd0)},
+			nil,
+		},
+		okabeIto)
 
-	m.ColorConfigs = []*ColorConfig{eightColorConfig}
+	// red looks dark as well, the warnings use the bright yellow
+	protanopiaColorConfig := newColorblindConfig("protanopia",
+		[]Color{skyBlue, white, skyBlue, yellow, skyBlue, orange, yellow, gray},
+		[]Color{orange, skyBlue, RGBColor(0xc0, 0xc0, 0xc0), blue},
+		[][]Color{
+			{RGBColor(0x5a, 0x3e, 0x00), orange},
+			{RGBColor(0x1f, 0x4b, 0x66), skyBlue},
+			{RGBColor(0x4a, 0x4a, 0x4a), RGBColor(0xd0, 0xd0, 0xd0)},
+			nil,
+		},
+		okabeIto)
+
+	// blue and green, yellow and violet look alike, red and cyan do not
+	cyan, red, pink := RGBColor(0x40, 0xc0, 0xc0), RGBColor(0xff, 0x40, 0x40), RGBColor(0xff, 0x99, 0xcc)
+	tritanopiaColorConfig := newColorblindConfig("tritanopia",
+		[]Color{cyan, white, cyan, red, cyan, pink, red, gray},
+		[]Color{vermillion, cyan, RGBColor(0xc0, 0xc0, 0xc0), bluishGreen},
+		[][]Color{
+			{RGBColor(0x55, 0x1f, 0x00), vermillion},
+			{RGBColor(0x15, 0x40, 0x40), cyan},
+			{RGBColor(0x4a, 0x4a, 0x4a), RGBColor(0xd0, 0xd0, 0xd0)},
+			nil,
+		},
+		[]Color{vermillion, cyan, pink, white, bluishGreen, red, gray})
+
+	m.ColorConfigs = []*ColorConfig{eightColorConfig, paletteColorConfig,
+		deuteranopiaColorConfig, protanopiaColorConfig, tritanopiaColorConfig}
 
 	asciiSymbolConfig := new(SymbolConfig)
-	asciiSymbolConfig.Name = "ascii"
+	asciiSymbolConfig.Name = SymbolSetASCII
 	asciiSymbolConfig.Types = map[string]string{
 		"resource":         string([]rune{32, 188, 189, 190}),
 		"belt":             string([]rune{226, 224, 225, 234, 232, 233, 238, 236, 237, 244, 242, 243}),
@@ -42,10 +133,26 @@ func newDisplayConfigManager() *DisplayConfigManager {
 		"cornerTriangle":   "/\\/\\",
 		"undergroundEntry": "-|-|",
 		"undergroundExit":  "V<A>",
+		"water":            "~",
+		"pipe":             "+",
+		"tank":             "O",
+		"pump":             "v<^>",
+		"fluidInput":       "=|=|",
+		"rail":             "#",
+		"station":          "@",
+		"locomotive":       "L",
+		"wagon":            "W",
+		"providerChest":    "P",
+		"requesterChest":   "R",
+		"storageChest":     "S",
+		"bot":              "*",
+		"arithmetic":       "%",
+		"decider":          "?",
+		"invalid":          "x",
 	}
 
 	unicodeSymbolConfig := new(SymbolConfig)
-	unicodeSymbolConfig.Name = "FreeMono"
+	unicodeSymbolConfig.Name = SymbolSetFreeMono
 	unicodeSymbolConfig.Types = map[string]string{
 		"resource":         " \u2591\u2592\u2593",
 		"belt":             "\u2193\u21B2\u21B3\u2190\u2196\u2199\u2191\u21B1\u21B0\u2192\u2198\u2197",
@@ -60,6 +167,22 @@ func newDisplayConfigManager() *DisplayConfigManager {
 		"cornerTriangle":   "\u25E2\u25E3\u25E4\u25E5",
 		"undergroundEntry": "\u2565\u2561\u2568\u255E",
 		"undergroundExit":  "\u21D3\u21D0\u21D1\u21D2",
+		"water":            "\u2248",
+		"pipe":             "\u256C",
+		"tank":             "\u25C9",
+		"pump":             "\u25BC\u25C0\u25B2\u25B6",
+		"fluidInput":       "\u2550\u2551\u2550\u2551",
+		"rail":             "\u256A",
+		"station":          "\u25A9",
+		"locomotive":       "\u25A0",
+		"wagon":            "\u25A1",
+		"providerChest":    "\u229E",
+		"requesterChest":   "\u229F",
+		"storageChest":     "\u22A1",
+		"bot":              "\u2736",
+		"arithmetic":       "\u2A01",
+		"decider":          "\u2A76",
+		"invalid":          "\u2717",
 	}
 
 	m.SymbolConfigs = []*SymbolConfig{unicodeSymbolConfig, asciiSymbolConfig}
@@ -74,13 +197,69 @@ func newDisplayConfigManager() *DisplayConfigManager {
 type SymbolConfig struct {
 	Name  string
 	Types map[string]string
+	// Products the glyphs of the Product-s set by the SymbolConfig, the other Product-s keep their representation
+	Products map[*Product]rune
+}
+
+// StructureColor the color and the SGR attribute of a StructureTile in a DisplayMode
+type StructureColor struct {
+	Color     Color
+	Attribute int
+}
+
+// newColorblindConfig creates a ColorConfig with a Color for each DisplayMode, the blocked heatmap is reversed to
+// tell it apart without its color
+func newColorblindConfig(name string, structureColors, resourceColors []Color, gradients [][]Color, productColors []Color) *ColorConfig {
+	c := new(ColorConfig)
+	c.Name = name
+
+	c.StructureColors = make([]StructureColor, len(structureColors))
+	for i, color := range structureColors {
+		c.StructureColors[i] = StructureColor{color, 1}
+	}
+	c.StructureColors[DisplayModeStatusBlocked].Attribute = 7
+
+	c.ResourceColors = resourceColors
+	c.ResourceGradients = gradients
+	c.ProductColors = productColors
+
+	return c
 }
 
 // ColorConfig a configuration containing the colors used for StructureTile-s
 type ColorConfig struct {
 	Name            string
-	StructureColors [][]int
-	ResourceColors  []int
+	StructureColors []StructureColor
+	ResourceColors  []Color
+	// ResourceGradients the colors of the poorest and of the richest tiles of each resource, used from 256 colors,
+	// a nil entry keeps the resource color
+	ResourceGradients [][]Color
+	// ProductColors the colors of the Product-s on the map in the canonical order, used from 256 colors,
+	// nil keeps the structure colors
+	ProductColors []Color
+}
+
+// ResourceColor returns the Color of a resource tile, a gradient shows its richness when the terminal allows it
+func (c *ColorConfig) ResourceColor(resource, amount int, mode ColorMode) Color {
+	if resource == -1 {
+		return c.ResourceColors[0]
+	}
+
+	if mode >= ColorMode256 && c.ResourceGradients != nil && c.ResourceGradients[resource] != nil {
+		gradient := c.ResourceGradients[resource]
+		return gradient[0].Blend(gradient[1], float64(amount)/ResourceRichness)
+	}
+
+	return c.ResourceColors[resource]
+}
+
+// ProductColor returns the Color of the Product on the map and true, or false if the structure color is kept
+func (c *ColorConfig) ProductColor(p *Product, mode ColorMode) (Color, bool) {
+	if mode == ColorMode8 || len(c.ProductColors) == 0 {
+		return Color{}, false
+	}
+
+	return c.ProductColors[GlobalProductFactory.Index(p)%len(c.ProductColors)], true
 }
 
 // GetColorConfig returns the current ColorConfig
@@ -102,6 +281,17 @@ func (m *DisplayConfigManager) SetColorConfig(index int) {
 	m.sColorConfig = m.ColorConfigs[index]
 }
 
+// ColorConfigIndex returns the index of the ColorConfig with the name, -1 if there is none
+func (m *DisplayConfigManager) ColorConfigIndex(name string) int {
+	for i, c := range m.ColorConfigs {
+		if c.Name == name {
+			return i
+		}
+	}
+
+	return -1
+}
+
 // GetSymbolConfig returns the current SymbolConfig
 func (m *DisplayConfigManager) GetSymbolConfig() *SymbolConfig {
 	return m.sSymbolConfig
@@ -120,3 +310,40 @@ func (m *DisplayConfigManager) SetSymbolConfig(index int) {
 
 	m.sSymbolConfig = m.SymbolConfigs[index]
 }
+
+// SymbolConfigIndex returns the index of the SymbolConfig with the name, -1 if there is none
+func (m *DisplayConfigManager) SymbolConfigIndex(name string) int {
+	for i, c := range m.SymbolConfigs {
+		if c.Name == name {
+			return i
+		}
+	}
+
+	return -1
+}
+
+// InvalidSymbol returns the mark of the invalid ghosts of the current SymbolConfig
+func (m *DisplayConfigManager) InvalidSymbol() rune {
+	symbols, ok := m.sSymbolConfig.Types["invalid"]
+	if !ok {
+		return 'x'
+	}
+
+	symbol, _ := utf8.DecodeRuneInString(symbols)
+	return symbol
+}
+
+// cueBlink indicates if the blinking cues show their mark, it switches every CueBlinkPeriod
+func cueBlink() bool {
+	return time.Now().UnixNano()/int64(CueBlinkPeriod)%2 == 0
+}
+
+// ProductSymbol returns the glyph of the Product in the symbol set, colored as on a belt
+func (m *DisplayConfigManager) ProductSymbol(p *Product, symbolSet string) string {
+	color := m.sColorConfig.StructureColors[DisplayModeMap]
+	if productColor, ok := m.sColorConfig.ProductColor(p, m.ColorMode); ok {
+		color.Color = productColor
+	}
+
+	return fmt.Sprintf("\033[%s;%dm%c\033[0m", color.Color.SGR(m.ColorMode), color.Attribute, p.Glyph(symbolSet))
+}
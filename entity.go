@@ -2,6 +2,7 @@ package main
 
 import (
 	"fmt"
+	"strconv"
 	"unicode/utf8"
 )
 
@@ -54,6 +55,18 @@ const (
 
 	// DisplayModeGhostInvalid invalid representation when placing on the map
 	DisplayModeGhostInvalid
+
+	// DisplayModeStatusWorking heatmap representation of a working Structure
+	DisplayModeStatusWorking
+
+	// DisplayModeStatusStarved heatmap representation of a Structure waiting for input
+	DisplayModeStatusStarved
+
+	// DisplayModeStatusBlocked heatmap representation of a Structure unable to deliver its output
+	DisplayModeStatusBlocked
+
+	// DisplayModeStatusIdle heatmap representation of a disabled Structure or a Factory without a Recipe
+	DisplayModeStatusIdle
 )
 
 // Tile is a component of the game map
@@ -149,7 +162,7 @@ func (b *BaseStructureTile) SetProduct(p *Product) {
 func (b *BaseStructureTile) Display(mode DisplayMode) string {
 	var symbol rune
 	if b.product != nil {
-		symbol = b.product.representation
+		symbol = b.product.Glyph(GlobalDisplayConfigManager.GetSymbolConfig().Name)
 	} else {
 		symbolConfig := GlobalDisplayConfigManager.GetSymbolConfig()
 		symbols := symbolConfig.Types[b.symbolID]
@@ -168,9 +181,27 @@ func (b *BaseStructureTile) Display(mode DisplayMode) string {
 		}
 	}
 
-	symbolColors := GlobalDisplayConfigManager.GetColorConfig().StructureColors[mode]
+	m := GlobalDisplayConfigManager
+	symbolColor := m.GetColorConfig().StructureColors[mode]
+
+	color := symbolColor.Color
+	if b.product != nil && mode == DisplayModeMap {
+		if productColor, ok := m.GetColorConfig().ProductColor(b.product, m.ColorMode); ok {
+			color = productColor
+		}
+	}
 
-	return fmt.Sprintf("\033[%d;%dm%c\033[0m", symbolColors[0], symbolColors[1], symbol)
+	attributes := strconv.Itoa(symbolColor.Attribute)
+	if m.Cues && (mode == DisplayModeMapSelected || mode == DisplayModeGhostInvalid) {
+		attributes += ";4"
+
+		// the terminal library cannot blink, the glyph alternates with the invalid mark instead
+		if mode == DisplayModeGhostInvalid && cueBlink() {
+			symbol = m.InvalidSymbol()
+		}
+	}
+
+	return fmt.Sprintf("\033[%s;%sm%c\033[0m", color.SGR(m.ColorMode), attributes, symbol)
 }
 
 // BaseStructure is a basic implementation of Structure
@@ -178,6 +209,7 @@ type BaseStructure struct {
 	tiles   [][]StructureTile
 	inputs  []Transfer
 	outputs []Transfer
+	fluids  []Transfer
 }
 
 // Inputs return Transfer point of the Structure where the inputs are expected to come from
@@ -190,6 +222,11 @@ func (s *BaseStructure) Outputs() []Transfer {
 	return s.outputs
 }
 
+// FluidConnections return the Transfer points of the Structure where pipes can be connected
+func (s *BaseStructure) FluidConnections() []Transfer {
+	return s.fluids
+}
+
 // Tiles return the Tiles associated with the BaseStructure
 func (s *BaseStructure) Tiles() [][]StructureTile {
 	return s.tiles
@@ -223,6 +260,11 @@ func (s *BaseStructure) RotateRight() {
 		s.outputs[i].d = (output.d + 1) % 4
 	}
 
+	for i, fluid := range s.fluids {
+		s.fluids[i].x, s.fluids[i].y = height-1-fluid.y, fluid.x
+		s.fluids[i].d = (fluid.d + 1) % 4
+	}
+
 	s.tiles = newTiles
 }
 
@@ -254,6 +296,11 @@ func (s *BaseStructure) RotateLeft() {
 		s.outputs[i].d = (output.d + 3) % 4
 	}
 
+	for i, fluid := range s.fluids {
+		s.fluids[i].x, s.fluids[i].y = fluid.y, width-1-fluid.x
+		s.fluids[i].d = (fluid.d + 3) % 4
+	}
+
 	s.tiles = newTiles
 }
 
@@ -284,6 +331,11 @@ func (s *BaseStructure) copyStructure(parent Structure) *BaseStructure {
 		structure.outputs[i] = output
 	}
 
+	structure.fluids = make([]Transfer, len(s.fluids))
+	for i, fluid := range s.fluids {
+		structure.fluids[i] = fluid
+	}
+
 	return structure
 }
 
@@ -345,8 +397,15 @@ func NewOutputTile(pos int) *OutputTile {
 // Extractor Structure that extracts a RawResource from the ground
 type Extractor struct {
 	BaseStructure
-	counter int
-	product *Product
+	counter  int
+	product  *Product
+	next     int
+	filter   int
+	depleted bool
+
+	// resourceCache the RawResource-s left to extract, computed again when cached is false
+	resourceCache []*RawResource
+	cached        bool
 }
 
 // NewExtractor creates a new *Extractor
@@ -363,6 +422,8 @@ func NewExtractor() *Extractor {
 
 	block.outputs[0] = Transfer{x: 1, y: 2, d: DirectionDown}
 
+	block.filter = -1
+
 	return block
 }
 
@@ -370,6 +431,7 @@ func NewExtractor() *Extractor {
 func (e *Extractor) CopyStructure() Structure {
 	extractor := new(Extractor)
 	extractor.counter = 0
+	extractor.filter = e.filter
 
 	baseStructure := e.BaseStructure.copyStructure(extractor)
 	extractor.BaseStructure = *baseStructure
@@ -419,28 +481,110 @@ func (e *Extractor) Tick() {
 		return
 	}
 
+	resources := e.resources()
+	size := len(resources)
+
 	var rawResource *RawResource
-	foundTile := false
-	for _, tiles := range e.Tiles() {
-		for _, tile := range tiles {
-			res := tile.UnderlyingResource()
-			if res.amount > 0 {
-				rawResource = res
-				foundTile = true
-				break
-			}
-		}
-		if foundTile {
+	for i := 0; i < size; i++ {
+		index := (e.next + i) % size
+		if resources[index].amount > 0 {
+			rawResource = resources[index]
+			e.next = (index + 1) % size
 			break
 		}
 	}
 
 	if rawResource == nil {
+		e.depleted = true
 		return
 	}
 
+	e.depleted = false
 	rawResource.amount--
 	e.product = GlobalProductFactory.GetProduct(rawResource.resource)
+
+	if rawResource.amount == 0 {
+		e.cached = false
+	}
+}
+
+// resources returns the RawResource-s under the Extractor that match the filter and are not depleted
+func (e *Extractor) resources() []*RawResource {
+	if e.cached {
+		return e.resourceCache
+	}
+
+	resources := make([]*RawResource, 0)
+	for _, tiles := range e.Tiles() {
+		for _, tile := range tiles {
+			if tile == nil {
+				continue
+			}
+
+			res := tile.UnderlyingResource()
+			if res == nil || res.resource == -1 || res.resource == ResourceWater {
+				continue
+			}
+
+			if e.filter != -1 && res.resource != e.filter {
+				continue
+			}
+
+			if res.amount > 0 {
+				resources = append(resources, res)
+			}
+		}
+	}
+
+	e.resourceCache = resources
+	e.cached = true
+
+	return resources
+}
+
+// Remaining returns the amount of resources the Extractor can still extract
+func (e *Extractor) Remaining() int {
+	total := 0
+	for _, res := range e.resources() {
+		total += res.amount
+	}
+
+	return total
+}
+
+// TicksToDepletion estimates the number of ticks until the Extractor runs out of resources
+func (e *Extractor) TicksToDepletion() int {
+	return e.Remaining() * CycleSizeExtractor
+}
+
+// Depleted indicates if the Extractor stopped producing for lack of resources
+func (e *Extractor) Depleted() bool {
+	return e.depleted
+}
+
+// Filter returns the resource the Extractor is locked to, -1 if any resource is extracted
+func (e *Extractor) Filter() int {
+	return e.filter
+}
+
+// SetFilter locks the Extractor to a single resource, -1 allows any resource
+func (e *Extractor) SetFilter(resource int) {
+	e.filter = resource
+	e.next = 0
+	e.depleted = false
+	e.cached = false
+}
+
+// NextFilter cycles the Extractor through the resources it can be locked to
+func (e *Extractor) NextFilter() {
+	switch e.filter {
+	case -1:
+		e.SetFilter(ProductResourceCopper)
+	case ProductResourceStone:
+		e.SetFilter(-1)
+	default:
+		e.SetFilter(e.filter + 1)
+	}
 }
 
 // GetCode return the code for the Extractor type
@@ -454,23 +598,32 @@ type ChestTile struct {
 }
 
 // NewChestTile creates a new *ChestTile
-func NewChestTile() *ChestTile {
-	return &ChestTile{BaseStructureTile{0, 1, "chest", nil, nil, nil}}
+func NewChestTile(symbolID string) *ChestTile {
+	return &ChestTile{BaseStructureTile{0, 1, symbolID, nil, nil, nil}}
 }
 
 // Chest is the structure representation of a storage chest
 type Chest struct {
 	BaseStructure
 	s *Storage
+
+	kind     int
+	requests map[*Product]int
 }
 
 // NewChest creates a new *Chest
 func NewChest() *Chest {
+	return newChest(ChestKindPlain, "chest")
+}
+
+func newChest(kind int, symbolID string) *Chest {
 	chest := new(Chest)
 	chest.tiles = [][]StructureTile{
-		{NewChestTile()},
+		{NewChestTile(symbolID)},
 	}
 	chest.s = NewStorage(ChestMaxStorage)
+	chest.kind = kind
+	chest.requests = make(map[*Product]int)
 
 	chest.inputs = make([]Transfer, 4)
 	chest.outputs = make([]Transfer, 0)
@@ -517,6 +670,8 @@ func (c *Chest) AcceptProduct(p *Product) bool {
 func (c *Chest) CopyStructure() Structure {
 	chest := new(Chest)
 	chest.s = NewStorage(ChestMaxStorage)
+	chest.kind = c.kind
+	chest.requests = make(map[*Product]int)
 
 	baseStructure := c.BaseStructure.copyStructure(chest)
 	chest.BaseStructure = *baseStructure
@@ -533,7 +688,16 @@ func (c *Chest) RotateLeft() {
 }
 
 // GetCode return the code for the Chest type
-func (*Chest) GetCode() int {
+func (c *Chest) GetCode() int {
+	switch c.kind {
+	case ChestKindProvider:
+		return ProductStructureProviderChest
+	case ChestKindRequester:
+		return ProductStructureRequesterChest
+	case ChestKindStorage:
+		return ProductStructureStorageChest
+	}
+
 	return ProductStructureChest
 }
 
@@ -683,6 +847,40 @@ func (b *Belt) setTransfers() {
 	b.outputs[0].d = exit % 4
 }
 
+// directionBetween returns the Direction of the movement between two neighbouring positions
+func directionBetween(from, to position) Direction {
+	switch {
+	case to.y > from.y:
+		return DirectionDown
+	case to.x < from.x:
+		return DirectionLeft
+	case to.y < from.y:
+		return DirectionUp
+	}
+
+	return DirectionRight
+}
+
+// beltRotation returns the Belt rotation position that receives Product-s moving in the entry
+// Direction and sends them out in the exit Direction, the exit cannot be opposite to the entry
+func beltRotation(entry, exit Direction) int {
+	variant := 0
+	switch (exit + 4 - entry) % 4 {
+	case 1:
+		variant = 1
+	case 3:
+		variant = 2
+	}
+
+	return int(entry)*3 + variant
+}
+
+// ResourceWater the resource of water tiles, usable only by an OffshorePump
+const ResourceWater int = 3
+
+// ResourceRichness the amount of the richest resource tiles of a new map
+const ResourceRichness = 300
+
 // RawResource is a Tile containing natural resources
 type RawResource struct {
 	amount   int
@@ -697,7 +895,9 @@ func (t *RawResource) Display(mode DisplayMode) string {
 	var symbol rune
 	var width int
 	repeat := 0
-	if t.amount > 0 {
+	if t.resource == ResourceWater {
+		symbols = symbolConfig.Types["water"]
+	} else if t.amount > 0 {
 		repeat = t.amount/100 + 1
 	}
 
@@ -711,21 +911,16 @@ func (t *RawResource) Display(mode DisplayMode) string {
 		repeat--
 	}
 
-	var symbolColor int
-	colors := GlobalDisplayConfigManager.GetColorConfig().ResourceColors
-	if t.resource == -1 {
-		symbolColor = colors[0]
-	} else {
-		symbolColor = colors[t.resource]
-	}
+	m := GlobalDisplayConfigManager
+	symbolColor := m.GetColorConfig().ResourceColor(t.resource, t.amount, m.ColorMode)
 
 	colorMode := 4
 	if mode == DisplayModeMapSelected {
-		symbolColor = 37
+		symbolColor = ANSIColor(37)
 		colorMode = 7
 	}
 
-	return fmt.Sprintf("\033[%d;%dm%c\033[0m", symbolColor, colorMode, symbol)
+	return fmt.Sprintf("\033[%s;%dm%c\033[0m", symbolColor.SGR(m.ColorMode), colorMode, symbol)
 }
 
 // SplitterLeftTile the left component of the Splitter
@@ -864,6 +1059,7 @@ type Factory struct {
 	recipe     *Recipe
 	inProducts map[*Product]int
 	counter    int
+	box        *FluidBox
 }
 
 // NewFactory creates a new *Splitter
@@ -871,18 +1067,22 @@ func NewFactory() *Factory {
 	block := new(Factory)
 	block.tiles = [][]StructureTile{
 		{NewInputTile(2), NewFillerCenterTile(0), NewInputTile(2)},
-		{NewFillerCenterTile(0), NewFillerCenterTile(0), NewFillerCenterTile(0)},
+		{NewFluidInputTile(0), NewFillerCenterTile(0), NewFluidInputTile(0)},
 		{NewTriangleCornerTile(3), NewOutputTile(0), NewTriangleCornerTile(2)},
 	}
 
 	block.inputs = make([]Transfer, 2)
 	block.outputs = make([]Transfer, 1)
+	block.fluids = make([]Transfer, 2)
 
 	block.inputs[0] = Transfer{x: 0, y: 0, d: DirectionDown}
 	block.inputs[1] = Transfer{x: 2, y: 0, d: DirectionDown}
 	block.outputs[0] = Transfer{x: 1, y: 2, d: DirectionDown}
+	block.fluids[0] = Transfer{x: 0, y: 1, d: DirectionLeft}
+	block.fluids[1] = Transfer{x: 2, y: 1, d: DirectionRight}
 
 	block.inProducts = make(map[*Product]int, 0)
+	block.setFluidBox()
 
 	return block
 }
@@ -894,10 +1094,33 @@ func (f *Factory) CopyStructure() Structure {
 	baseStructure := f.BaseStructure.copyStructure(factory)
 	factory.BaseStructure = *baseStructure
 	factory.recipe = f.recipe
+	factory.inProducts = make(map[*Product]int)
+	factory.setFluidBox()
 
 	return factory
 }
 
+// FluidBox returns the FluidBox that receives the Fluid inputs of the Factory
+func (f *Factory) FluidBox() *FluidBox {
+	return f.box
+}
+
+func (f *Factory) setFluidBox() {
+	f.box = NewFluidBox(0)
+	f.box.inputOnly = true
+
+	if f.recipe == nil {
+		return
+	}
+
+	for _, fluid := range f.recipe.fluidOrder {
+		// a single FluidBox is available, so only one Fluid input is supported
+		f.box.filter = fluid
+		f.box.capacity = 2 * f.recipe.fluidInput[fluid]
+		break
+	}
+}
+
 // CanRetrieveProduct indicates if the internal Product can be extracted
 func (f *Factory) CanRetrieveProduct() (*Product, bool) {
 	if f.recipe == nil {
@@ -996,6 +1219,16 @@ func (f *Factory) Tick() {
 		}
 	}
 
+	for fluid, amount := range f.recipe.fluidInput {
+		if f.box.Fluid() != fluid || f.box.Amount() < amount {
+			return
+		}
+	}
+
+	for _, amount := range f.recipe.fluidInput {
+		f.box.Remove(amount)
+	}
+
 	f.inProducts = make(map[*Product]int)
 	f.counter = 1
 }
@@ -1005,10 +1238,16 @@ func (f *Factory) SetRecipe(r *Recipe) {
 	f.counter = 0
 	f.inProducts = make(map[*Product]int)
 	f.recipe = r
+	f.setFluidBox()
+
+	var output *Product
+	if r != nil {
+		output = r.output
+	}
 
 	switch bst := f.BaseStructure.tiles[1][1].(type) {
 	case *BaseStructureTile:
-		bst.SetProduct(r.output)
+		bst.SetProduct(output)
 	}
 }
 
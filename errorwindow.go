@@ -14,7 +14,6 @@ type ErrorWindow struct {
 // Layout displays the ErrorWindow
 func (w *ErrorWindow) Layout(g *gocui.Gui) error {
 	g.Cursor = false
-	g.Mouse = false
 
 	maxX, maxY := g.Size()
 
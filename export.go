@@ -0,0 +1,135 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+)
+
+// RecipeGraphInput one input of a Recipe in the exported recipe graph
+type RecipeGraphInput struct {
+	Name  string `json:"name"`
+	Count int    `json:"count"`
+	Fluid bool   `json:"fluid,omitempty"`
+}
+
+// RecipeGraphNode a Product or Fluid in the exported recipe graph
+type RecipeGraphNode struct {
+	Name            string             `json:"name"`
+	Symbol          string             `json:"symbol"`
+	Fluid           bool               `json:"fluid,omitempty"`
+	ProducedBy      string             `json:"producedBy"`
+	ProductionTicks int                `json:"productionTicks,omitempty"`
+	Inputs          []RecipeGraphInput `json:"inputs,omitempty"`
+	ConsumedBy      []string           `json:"consumedBy"`
+}
+
+// RecipeGraph the Product-s and Fluid-s with the Recipe-s linking them
+type RecipeGraph struct {
+	Nodes []*RecipeGraphNode `json:"nodes"`
+}
+
+// NewRecipeGraph builds the RecipeGraph from GlobalProductFactory and GlobalRecipeFactory
+func NewRecipeGraph() *RecipeGraph {
+	graph := new(RecipeGraph)
+	graph.Nodes = make([]*RecipeGraphNode, 0)
+
+	nodes := make(map[string]*RecipeGraphNode)
+	for _, p := range GlobalProductFactory.cannonicalOrder {
+		// Product-s without a Recipe are raw resources
+		node := &RecipeGraphNode{Name: p.name, Symbol: string(p.representation), ProducedBy: "extractor", ConsumedBy: make([]string, 0)}
+		graph.Nodes = append(graph.Nodes, node)
+		nodes[p.name] = node
+	}
+
+	water := GlobalFluidFactory.GetFluid(FluidWater)
+	node := &RecipeGraphNode{Name: water.name, Symbol: string(water.representation), Fluid: true, ProducedBy: "pump", ConsumedBy: make([]string, 0)}
+	graph.Nodes = append(graph.Nodes, node)
+	nodes[water.name] = node
+
+	for _, recipe := range GlobalRecipeFactory.Assembly {
+		node := nodes[recipe.output.name]
+		node.ProducedBy = "factory"
+		node.ProductionTicks = recipe.productionTicks
+
+		for _, p := range recipe.inputOrder {
+			node.Inputs = append(node.Inputs, RecipeGraphInput{p.name, recipe.input[p], false})
+			nodes[p.name].ConsumedBy = append(nodes[p.name].ConsumedBy, node.Name)
+		}
+
+		for _, f := range recipe.fluidOrder {
+			node.Inputs = append(node.Inputs, RecipeGraphInput{f.name, recipe.fluidInput[f], true})
+			nodes[f.name].ConsumedBy = append(nodes[f.name].ConsumedBy, node.Name)
+		}
+	}
+
+	return graph
+}
+
+// WriteJSON writes the RecipeGraph as indented JSON
+func (graph *RecipeGraph) WriteJSON(w io.Writer) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+
+	return encoder.Encode(graph)
+}
+
+// WriteDOT writes the RecipeGraph in the Graphviz DOT format, edges go from the inputs to the outputs
+func (graph *RecipeGraph) WriteDOT(w io.Writer) error {
+	if _, err := fmt.Fprint(w, "digraph recipes {\n\trankdir=LR;\n"); err != nil {
+		return err
+	}
+
+	for _, node := range graph.Nodes {
+		label := fmt.Sprintf("%s\n%s", node.Name, node.ProducedBy)
+		if node.ProductionTicks != 0 {
+			label = fmt.Sprintf("%s %d ticks", label, node.ProductionTicks)
+		}
+
+		shape := "box"
+		if node.Fluid {
+			shape = "ellipse"
+		}
+
+		if _, err := fmt.Fprintf(w, "\t%q [shape=%s label=%q];\n", node.Name, shape, label); err != nil {
+			return err
+		}
+	}
+
+	for _, node := range graph.Nodes {
+		for _, input := range node.Inputs {
+			if _, err := fmt.Fprintf(w, "\t%q -> %q [label=\"%d\"];\n", input.Name, node.Name, input.Count); err != nil {
+				return err
+			}
+		}
+	}
+
+	_, err := fmt.Fprint(w, "}\n")
+
+	return err
+}
+
+// runCommand executes the command line subcommand, the arguments exclude the program name
+func runCommand(args []string, out io.Writer) error {
+	switch args[0] {
+	case "export-recipes":
+		flags := flag.NewFlagSet(args[0], flag.ContinueOnError)
+		format := flags.String("format", "dot", "output format, dot or json")
+		if err := flags.Parse(args[1:]); err != nil {
+			return err
+		}
+
+		graph := NewRecipeGraph()
+		switch *format {
+		case "dot":
+			return graph.WriteDOT(out)
+		case "json":
+			return graph.WriteJSON(out)
+		}
+
+		return fmt.Errorf("unknown format %q, expected dot or json", *format)
+	}
+
+	return fmt.Errorf("unknown command %q, expected export-recipes", args[0])
+}
@@ -0,0 +1,368 @@
+package main
+
+const (
+	// PumpRate the amount of fluid extracted by an OffshorePump each tick
+	PumpRate int = 5
+	// PumpCapacity the amount of fluid stored by an OffshorePump
+	PumpCapacity int = 100
+	// PipeCapacity the amount of fluid stored by a Pipe
+	PipeCapacity int = 100
+	// TankCapacity the amount of fluid stored by a Tank
+	TankCapacity int = 5000
+)
+
+// GlobalFluidFactory a global fluid factory that contains all the Fluids
+var GlobalFluidFactory = newFluidFactory()
+
+const (
+	// FluidWater water
+	FluidWater int = iota
+)
+
+// Fluid a liquid that is transported through pipes instead of belts
+type Fluid struct {
+	name           string
+	representation rune
+}
+
+// FluidFactory factory for generating all the possible Fluids
+type FluidFactory struct {
+	fluids map[int]*Fluid
+}
+
+// GetFluid returns the Fluid identified by the fluid id
+func (ff *FluidFactory) GetFluid(id int) *Fluid {
+	return ff.fluids[id]
+}
+
+func newFluidFactory() *FluidFactory {
+	ff := new(FluidFactory)
+	ff.fluids = make(map[int]*Fluid)
+
+	ff.fluids[FluidWater] = &Fluid{"water", '~'}
+
+	return ff
+}
+
+// FluidBox a container holding a single type of Fluid
+type FluidBox struct {
+	fluid     *Fluid
+	filter    *Fluid
+	amount    int
+	capacity  int
+	inputOnly bool
+}
+
+// NewFluidBox creates a new *FluidBox
+func NewFluidBox(capacity int) *FluidBox {
+	b := new(FluidBox)
+	b.capacity = capacity
+
+	return b
+}
+
+// Fluid returns the Fluid currently in the FluidBox
+func (b *FluidBox) Fluid() *Fluid {
+	return b.fluid
+}
+
+// Amount returns the amount of Fluid currently in the FluidBox
+func (b *FluidBox) Amount() int {
+	return b.amount
+}
+
+// Capacity returns the maximum amount of Fluid the FluidBox can hold
+func (b *FluidBox) Capacity() int {
+	return b.capacity
+}
+
+// CanAccept indicates if the FluidBox has space for the Fluid
+func (b *FluidBox) CanAccept(f *Fluid) bool {
+	if f == nil || b.amount >= b.capacity {
+		return false
+	}
+
+	if b.filter != nil && b.filter != f {
+		return false
+	}
+
+	return b.fluid == nil || b.fluid == f
+}
+
+// Add adds up to c units of Fluid, returning the amount actually added
+func (b *FluidBox) Add(f *Fluid, c int) int {
+	if !b.CanAccept(f) {
+		return 0
+	}
+
+	if b.amount+c > b.capacity {
+		c = b.capacity - b.amount
+	}
+
+	b.fluid = f
+	b.amount += c
+
+	return c
+}
+
+// Remove removes up to c units of Fluid, returning the amount actually removed
+func (b *FluidBox) Remove(c int) int {
+	if c > b.amount {
+		c = b.amount
+	}
+
+	b.amount -= c
+	if b.amount == 0 {
+		b.fluid = nil
+	}
+
+	return c
+}
+
+// Equalize moves Fluid towards the other FluidBox if it holds less
+func (b *FluidBox) Equalize(other *FluidBox) {
+	if b.inputOnly || b.amount <= other.amount {
+		return
+	}
+
+	if !other.CanAccept(b.fluid) {
+		return
+	}
+
+	// rounded up, so that a difference of one still flows
+	fluid := b.fluid
+	moved := b.Remove((b.amount - other.amount + 1) / 2)
+	added := other.Add(fluid, moved)
+	if added < moved {
+		b.fluid = fluid
+		b.amount += moved - added
+	}
+}
+
+// FluidStructure is a Structure that exchanges Fluid through pipe connection points
+type FluidStructure interface {
+	Structure
+	FluidConnections() []Transfer
+	FluidBox() *FluidBox
+}
+
+// PipeTile is the map representation of a pipe
+type PipeTile struct {
+	BaseStructureTile
+}
+
+// NewPipeTile creates a new *PipeTile
+func NewPipeTile() *PipeTile {
+	return &PipeTile{BaseStructureTile{0, 1, "pipe", nil, nil, nil}}
+}
+
+// TankTile is the map representation of a storage tank
+type TankTile struct {
+	BaseStructureTile
+}
+
+// NewTankTile creates a new *TankTile
+func NewTankTile() *TankTile {
+	return &TankTile{BaseStructureTile{0, 1, "tank", nil, nil, nil}}
+}
+
+// PumpTile is the map representation of an offshore pump
+type PumpTile struct {
+	BaseStructureTile
+}
+
+// NewPumpTile creates a new *PumpTile
+func NewPumpTile(pos int) *PumpTile {
+	return &PumpTile{BaseStructureTile{pos % 4, 4, "pump", nil, nil, nil}}
+}
+
+// FluidInputTile tile that indicates a pipe connection point of a Structure
+type FluidInputTile struct {
+	BaseStructureTile
+}
+
+// NewFluidInputTile creates a new *FluidInputTile
+func NewFluidInputTile(pos int) *FluidInputTile {
+	return &FluidInputTile{BaseStructureTile{pos % 4, 4, "fluidInput", nil, nil, nil}}
+}
+
+// BaseFluidStructure a Structure that only handles Fluid, no Product-s
+type BaseFluidStructure struct {
+	BaseStructure
+	box *FluidBox
+}
+
+// FluidBox returns the FluidBox of the BaseFluidStructure
+func (s *BaseFluidStructure) FluidBox() *FluidBox {
+	return s.box
+}
+
+// Tick does nothing for BaseFluidStructure, fluids are handled by the Game
+func (s *BaseFluidStructure) Tick() {
+}
+
+// CanRetrieveProduct does nothing for BaseFluidStructure, it does not handle Product-s
+func (s *BaseFluidStructure) CanRetrieveProduct() (*Product, bool) {
+	return nil, false
+}
+
+// RetrieveProduct does nothing for BaseFluidStructure, it does not handle Product-s
+func (s *BaseFluidStructure) RetrieveProduct() (*Product, bool) {
+	return nil, false
+}
+
+// CanAcceptProduct does nothing for BaseFluidStructure, it does not handle Product-s
+func (s *BaseFluidStructure) CanAcceptProduct(*Product) bool {
+	return false
+}
+
+// AcceptProduct does nothing for BaseFluidStructure, it does not handle Product-s
+func (s *BaseFluidStructure) AcceptProduct(*Product) bool {
+	return false
+}
+
+func (s *BaseFluidStructure) copyFluidStructure(parent Structure) *BaseFluidStructure {
+	structure := new(BaseFluidStructure)
+	structure.BaseStructure = *s.BaseStructure.copyStructure(parent)
+	structure.box = NewFluidBox(s.box.capacity)
+	structure.box.inputOnly = s.box.inputOnly
+
+	return structure
+}
+
+func allDirections() []Transfer {
+	return []Transfer{
+		{0, 0, DirectionDown},
+		{0, 0, DirectionLeft},
+		{0, 0, DirectionUp},
+		{0, 0, DirectionRight},
+	}
+}
+
+// Pipe Structure that transports Fluid to its neighbours
+type Pipe struct {
+	BaseFluidStructure
+}
+
+// NewPipe creates a new *Pipe
+func NewPipe() *Pipe {
+	block := new(Pipe)
+	block.tiles = [][]StructureTile{
+		{NewPipeTile()},
+	}
+
+	block.inputs = make([]Transfer, 0)
+	block.outputs = make([]Transfer, 0)
+	block.fluids = allDirections()
+
+	block.box = NewFluidBox(PipeCapacity)
+
+	return block
+}
+
+// CopyStructure creates a copy of the Pipe
+func (p *Pipe) CopyStructure() Structure {
+	pipe := new(Pipe)
+	pipe.BaseFluidStructure = *p.BaseFluidStructure.copyFluidStructure(pipe)
+
+	return pipe
+}
+
+// RotateRight does nothing for a Pipe
+func (p *Pipe) RotateRight() {
+}
+
+// RotateLeft does nothing for a Pipe
+func (p *Pipe) RotateLeft() {
+}
+
+// GetCode return the code for the Pipe type
+func (*Pipe) GetCode() int {
+	return ProductStructurePipe
+}
+
+// Tank Structure that stores large amounts of Fluid
+type Tank struct {
+	BaseFluidStructure
+}
+
+// NewTank creates a new *Tank
+func NewTank() *Tank {
+	block := new(Tank)
+	block.tiles = [][]StructureTile{
+		{NewTankTile()},
+	}
+
+	block.inputs = make([]Transfer, 0)
+	block.outputs = make([]Transfer, 0)
+	block.fluids = allDirections()
+
+	block.box = NewFluidBox(TankCapacity)
+
+	return block
+}
+
+// CopyStructure creates a copy of the Tank
+func (t *Tank) CopyStructure() Structure {
+	tank := new(Tank)
+	tank.BaseFluidStructure = *t.BaseFluidStructure.copyFluidStructure(tank)
+
+	return tank
+}
+
+// RotateRight does nothing for a Tank
+func (t *Tank) RotateRight() {
+}
+
+// RotateLeft does nothing for a Tank
+func (t *Tank) RotateLeft() {
+}
+
+// GetCode return the code for the Tank type
+func (*Tank) GetCode() int {
+	return ProductStructureTank
+}
+
+// OffshorePump Structure placed on water that fills its FluidBox with water
+type OffshorePump struct {
+	BaseFluidStructure
+}
+
+// NewOffshorePump creates a new *OffshorePump
+func NewOffshorePump() *OffshorePump {
+	block := new(OffshorePump)
+	block.tiles = [][]StructureTile{
+		{NewPumpTile(0)},
+	}
+
+	block.inputs = make([]Transfer, 0)
+	block.outputs = make([]Transfer, 0)
+	block.fluids = []Transfer{{0, 0, DirectionDown}}
+
+	block.box = NewFluidBox(PumpCapacity)
+
+	return block
+}
+
+// CopyStructure creates a copy of the OffshorePump
+func (p *OffshorePump) CopyStructure() Structure {
+	pump := new(OffshorePump)
+	pump.BaseFluidStructure = *p.BaseFluidStructure.copyFluidStructure(pump)
+
+	return pump
+}
+
+// Tick pumps water if the OffshorePump is placed on water
+func (p *OffshorePump) Tick() {
+	res := p.tiles[0][0].UnderlyingResource()
+	if res == nil || res.resource != ResourceWater {
+		return
+	}
+
+	p.box.Add(GlobalFluidFactory.GetFluid(FluidWater), PumpRate)
+}
+
+// GetCode return the code for the OffshorePump type
+func (*OffshorePump) GetCode() int {
+	return ProductStructurePump
+}
@@ -3,8 +3,12 @@ package main
 import (
 	"math"
 	"math/rand"
+	"time"
 )
 
+// TickDuration the amount of real time corresponding to one game Tick
+const TickDuration = 10 * time.Millisecond
+
 type position struct {
 	x int
 	y int
@@ -17,6 +21,30 @@ type Game struct {
 	splitters map[*Splitter]position
 	cursor    position
 	inventory *Storage
+	fluids    map[FluidStructure]position
+
+	stations     map[*Station]position
+	stationCount int
+	trains       []*Train
+	trainCount   int
+
+	logisticChests   map[*Chest]position
+	hubs             map[*LogisticHub]position
+	deliveries       []*Delivery
+	logisticsCounter int
+
+	wires         map[Structure]map[Structure]bool
+	conditions    map[Structure]*CircuitCondition
+	networks      []*CircuitNetwork
+	networkOf     map[Structure]*CircuitNetwork
+	circuitsDirty bool
+
+	history  *History
+	stats    *ProductionStats
+	statuses map[Structure]*statusHistory
+
+	alerts []*Alert
+	raised map[alertKey]*Alert
 }
 
 // WithinBounds indicates if the position is within the map limits
@@ -50,6 +78,22 @@ func (g *Game) MoveCursor(offsetX, offsetY int) bool {
 	return true
 }
 
+// EdgeDistance returns the number of tiles from the location to the first tile in the direction with another
+// kind of content, which is the edge of a Structure or of a resource patch, or to the edge of the map
+func (g *Game) EdgeDistance(x, y, dx, dy int) int {
+	kind := g.tileKind(y, x)
+
+	n := 0
+	for g.WithinBounds(x+(n+1)*dx, y+(n+1)*dy) {
+		n++
+		if g.tileKind(y+n*dy, x+n*dx) != kind {
+			break
+		}
+	}
+
+	return n
+}
+
 // GetStructureAt returns the Structure that covers the location and its top right corner
 func (g *Game) GetStructureAt(y, x int) (Structure, int, int) {
 	if !g.WithinBounds(x, y) {
@@ -75,9 +119,12 @@ func (g *Game) GetStructureAt(y, x int) (Structure, int, int) {
 
 // Tick advances the internal state of the game
 func (g *Game) Tick() {
+	g.tickCircuits()
+
 	// handle splitters first
 	for s := range g.splitters {
 		s.Tick()
+		g.recordStatus(s, true)
 	}
 
 	for s, p := range g.splitters {
@@ -118,7 +165,27 @@ func (g *Game) Tick() {
 		}
 		delete(inProgress, crt)
 
-		crt.Tick()
+		enabled := g.circuitEnabled(crt)
+		if enabled {
+			g.tickStructure(crt)
+		}
+		g.recordStatus(crt, enabled)
+
+		switch e := crt.(type) {
+		case *Extractor:
+			if e.Depleted() {
+				g.raiseAlert(AlertExtractorDepleted, e, p)
+			}
+		case *Chest:
+			if !e.CanAcceptProduct(nil) {
+				g.raiseAlert(AlertChestFull, e, p)
+			}
+		case *Factory:
+			if e.recipe == nil {
+				g.raiseAlert(AlertFactoryNoRecipe, e, p)
+			}
+		}
+
 		for _, input := range crt.Inputs() {
 			x := p.x + input.x
 			y := p.y + input.y
@@ -136,6 +203,11 @@ func (g *Game) Tick() {
 				inProgress[neighbour] = position{x: nx, y: ny}
 			}
 
+			if !enabled {
+				// disabled by the circuit network, it cannot consume input
+				continue
+			}
+
 			_, hasProduct := crt.CanRetrieveProduct()
 			if hasProduct {
 				// current structure has a product, so it cannot consume input
@@ -158,6 +230,65 @@ func (g *Game) Tick() {
 			continue
 		}
 	}
+
+	g.tickFluids()
+
+	for _, t := range g.trains {
+		t.Tick(g)
+	}
+
+	g.tickLogistics()
+
+	g.stats.Tick()
+	g.updateAlerts()
+}
+
+// tickFluids moves Fluid between connected FluidStructure-s
+func (g *Game) tickFluids() {
+	for s, p := range g.fluids {
+		box := s.FluidBox()
+		for _, connection := range s.FluidConnections() {
+			neighbour := g.GetFluidNeighbour(p.y+connection.y, p.x+connection.x, connection.d)
+			if neighbour == nil {
+				continue
+			}
+
+			box.Equalize(neighbour.FluidBox())
+		}
+	}
+}
+
+// GetFluidNeighbour returns the FluidStructure connected to the pipe connection point
+func (g *Game) GetFluidNeighbour(y int, x int, d Direction) FluidStructure {
+	switch d {
+	case DirectionDown:
+		y++
+	case DirectionLeft:
+		x--
+	case DirectionUp:
+		y--
+	case DirectionRight:
+		x++
+	}
+
+	neighbour, ny, nx := g.GetStructureAt(y, x)
+	if neighbour == nil {
+		return nil
+	}
+
+	fs, ok := neighbour.(FluidStructure)
+	if !ok {
+		return nil
+	}
+
+	opposite := (d + 2) % 4
+	for _, connection := range fs.FluidConnections() {
+		if nx+connection.x == x && ny+connection.y == y && connection.d == opposite {
+			return fs
+		}
+	}
+
+	return nil
 }
 
 // GetNeighbour returns a Structure that is linked to the Transfer point (in or out)
@@ -219,14 +350,32 @@ func (g *Game) RemoveStructure(y, x int) Structure {
 	structureTiles := s.Tiles()
 	for yy, tiles := range structureTiles {
 		for xx, tile := range tiles {
+			if tile == nil {
+				// the tunnel of an Underground, the map tile is not covered
+				continue
+			}
+
 			g.WorldMap[y+yy][x+xx] = tile.UnderlyingResource()
 		}
 	}
 
+	if fs, ok := s.(FluidStructure); ok {
+		delete(g.fluids, fs)
+	}
+
+	g.removeFromCircuits(s)
+	delete(g.statuses, s)
+
 	switch ss := s.(type) {
 	case *Splitter:
 		delete(g.splitters, ss)
 		return s
+	case *Station:
+		delete(g.stations, ss)
+	case *LogisticHub:
+		delete(g.hubs, ss)
+	case *Chest:
+		delete(g.logisticChests, ss)
 	}
 
 	for _, input := range s.Inputs() {
@@ -253,8 +402,8 @@ func (g *Game) RemoveStructure(y, x int) Structure {
 	return s
 }
 
-// PlaceStructure puts a Building at the specified location on the map
-func (g *Game) PlaceStructure(y, x int, s Structure) bool {
+// CanPlaceStructure indicates if the Structure can be placed at the specified location on the map
+func (g *Game) CanPlaceStructure(y, x int, s Structure) bool {
 	tilesMatrix := s.Tiles()
 
 	if y < 0 || y+len(tilesMatrix) >= len(g.WorldMap) {
@@ -265,6 +414,8 @@ func (g *Game) PlaceStructure(y, x int, s Structure) bool {
 		return false
 	}
 
+	_, isPump := s.(*OffshorePump)
+
 	for i, tiles := range tilesMatrix {
 		for j, tile := range tiles {
 			if tile == nil {
@@ -272,13 +423,29 @@ func (g *Game) PlaceStructure(y, x int, s Structure) bool {
 			}
 
 			t := g.WorldMap[y+i][x+j]
-			switch t.(type) {
+			switch r := t.(type) {
 			case StructureTile:
 				return false
+			case *RawResource:
+				// only pumps can be placed on water, and they must be placed on water
+				if isPump != (r.resource == ResourceWater) {
+					return false
+				}
 			}
 		}
 	}
 
+	return true
+}
+
+// PlaceStructure puts a Building at the specified location on the map
+func (g *Game) PlaceStructure(y, x int, s Structure) bool {
+	if !g.CanPlaceStructure(y, x, s) {
+		return false
+	}
+
+	tilesMatrix := s.Tiles()
+
 	for yy, tiles := range tilesMatrix {
 		for xx, tile := range tiles {
 			if tile == nil {
@@ -294,10 +461,25 @@ func (g *Game) PlaceStructure(y, x int, s Structure) bool {
 		}
 	}
 
+	if fs, ok := s.(FluidStructure); ok {
+		g.fluids[fs] = position{x: x, y: y}
+	}
+
 	switch ss := s.(type) {
 	case *Splitter:
 		g.splitters[ss] = position{x: x, y: y}
 		return true
+	case *Extractor:
+		// the resources under the Extractor change with its location
+		ss.cached = false
+	case *Station:
+		g.addStation(ss, position{x: x, y: y})
+	case *LogisticHub:
+		g.hubs[ss] = position{x: x, y: y}
+	case *Chest:
+		if ss.kind != ChestKindPlain {
+			g.logisticChests[ss] = position{x: x, y: y}
+		}
 	}
 
 	for _, input := range s.Inputs() {
@@ -346,6 +528,59 @@ func (g *Game) PlaceStructure(y, x int, s Structure) bool {
 	return true
 }
 
+// BeltLine creates the Belt-s following the path, each one feeding the next
+func BeltLine(path []position) []*Belt {
+	belts := make([]*Belt, len(path))
+	template := GlobalProductFactory.GetProduct(ProductStructureBelt).structure
+
+	for i, p := range path {
+		var entry, exit Direction
+		switch {
+		case len(path) == 1:
+			entry, exit = DirectionDown, DirectionDown
+		case i == 0:
+			exit = directionBetween(p, path[i+1])
+			entry = exit
+		case i == len(path)-1:
+			entry = directionBetween(path[i-1], p)
+			exit = entry
+		default:
+			entry = directionBetween(path[i-1], p)
+			exit = directionBetween(p, path[i+1])
+		}
+
+		belt := template.CopyStructure().(*Belt)
+		for r := beltRotation(entry, exit); r > 0; r-- {
+			belt.RotateRight()
+		}
+		belts[i] = belt
+	}
+
+	return belts
+}
+
+// PlaceBeltLine places Belt-s from the inventory along the path, skipping the occupied positions, as a single Action
+func (g *Game) PlaceBeltLine(path []position) int {
+	product := GlobalProductFactory.GetProduct(ProductStructureBelt)
+
+	actions := make(groupAction, 0, len(path))
+	for i, belt := range BeltLine(path) {
+		if g.inventory.objects[product] == 0 {
+			break
+		}
+
+		if a := g.buildStructure(path[i].y, path[i].x, belt); a != nil {
+			actions = append(actions, a)
+		}
+	}
+
+	if len(actions) != 0 {
+		g.history.record(actions)
+	}
+
+	return len(actions)
+}
+
 // GenerateGame creates a new game instance
 func GenerateGame(height int, width int) *Game {
 	if width <= 0 || height <= 0 {
@@ -356,13 +591,42 @@ func GenerateGame(height int, width int) *Game {
 	g.WorldMap = generateMap(height, width)
 	g.roots = make(map[Structure]position)
 	g.splitters = make(map[*Splitter]position)
-
-	g.inventory = NewStorage(100)
+	g.fluids = make(map[FluidStructure]position)
+	g.stations = make(map[*Station]position)
+	g.trains = make([]*Train, 0)
+	g.logisticChests = make(map[*Chest]position)
+	g.hubs = make(map[*LogisticHub]position)
+	g.deliveries = make([]*Delivery, 0)
+	g.wires = make(map[Structure]map[Structure]bool)
+	g.conditions = make(map[Structure]*CircuitCondition)
+	g.networks = make([]*CircuitNetwork, 0)
+	g.networkOf = make(map[Structure]*CircuitNetwork)
+	g.history = NewHistory()
+	g.stats = NewProductionStats()
+	g.statuses = make(map[Structure]*statusHistory)
+	g.alerts = make([]*Alert, 0)
+	g.raised = make(map[alertKey]*Alert)
+
+	g.inventory = NewStorage(400)
 	g.inventory.Add(GlobalProductFactory.GetProduct(ProductStructureBelt), 50)
 	g.inventory.Add(GlobalProductFactory.GetProduct(ProductStructureChest), 3)
 	g.inventory.Add(GlobalProductFactory.GetProduct(ProductStructureExtractor), 3)
 	g.inventory.Add(GlobalProductFactory.GetProduct(ProductStructureSplitter), 6)
 	g.inventory.Add(GlobalProductFactory.GetProduct(ProductStructureFactory), 8)
+	g.inventory.Add(GlobalProductFactory.GetProduct(ProductStructurePump), 2)
+	g.inventory.Add(GlobalProductFactory.GetProduct(ProductStructurePipe), 20)
+	g.inventory.Add(GlobalProductFactory.GetProduct(ProductStructureTank), 2)
+	g.inventory.Add(GlobalProductFactory.GetProduct(ProductStructureRail), 100)
+	g.inventory.Add(GlobalProductFactory.GetProduct(ProductStructureStation), 4)
+	g.inventory.Add(GlobalProductFactory.GetProduct(ProductLocomotive), 1)
+	g.inventory.Add(GlobalProductFactory.GetProduct(ProductWagon), 2)
+	g.inventory.Add(GlobalProductFactory.GetProduct(ProductStructureProviderChest), 2)
+	g.inventory.Add(GlobalProductFactory.GetProduct(ProductStructureRequesterChest), 2)
+	g.inventory.Add(GlobalProductFactory.GetProduct(ProductStructureStorageChest), 2)
+	g.inventory.Add(GlobalProductFactory.GetProduct(ProductStructureLogisticHub), 1)
+	g.inventory.Add(GlobalProductFactory.GetProduct(ProductStructureArithmeticCombinator), 2)
+	g.inventory.Add(GlobalProductFactory.GetProduct(ProductStructureDeciderCombinator), 2)
+	g.inventory.Add(GlobalProductFactory.GetProduct(ProductProcessedCopperWire), 20)
 
 	return g
 }
@@ -473,5 +737,31 @@ func generateMap(height, width int) [][]Tile {
 		}
 	}
 
+	lakes := 3 + rand.Int()%3
+	for index := 0; index < lakes; index++ {
+		x := rand.Int() % width
+		y := rand.Int() % height
+		ray := 3 + rand.Float64()*4
+
+		for i := y - int(ray); i <= y+int(ray); i++ {
+			for j := x - int(ray); j <= x+int(ray); j++ {
+				if i < 0 || j < 0 || i >= height || j >= width {
+					continue
+				}
+
+				if distance(x, y, j, i) > ray {
+					continue
+				}
+
+				// lakes do not cover resource patches
+				if worldMap[i][j].(*RawResource).resource != -1 {
+					continue
+				}
+
+				worldMap[i][j] = &RawResource{0, ResourceWater}
+			}
+		}
+	}
+
 	return worldMap
 }
@@ -2,6 +2,8 @@ package main
 
 import (
 	"fmt"
+	"strings"
+	"time"
 
 	"github.com/jroimartin/gocui"
 )
@@ -13,12 +15,63 @@ const (
 	stateMoveFromInventory
 	stateMoveFromStructure
 	stateSetRecipe
+	stateEditSchedule
+	stateSetRequests
+	stateEditCircuit
+	stateBlueprintSelect
+	stateBlueprintGhost
+	stateBlueprintLibrary
+	stateBlueprintExport
+	stateBlueprintImport
+	stateBeltDrag
+	stateDeconstructSelect
+	stateDeconstructConfirm
+	stateRouteTarget
+	stateRouteConfirm
+	stateOverview
+)
+
+const (
+	overlayNone int = iota
+	overlayLogistics
+	overlayCircuit
+	overlayHeatmap
+	overlayCount
 )
 
 type state struct {
-	state int
-	ghost Structure
-	st    []*Storage
+	state    int
+	ghost    Structure
+	st       []*Storage
+	train    *Train
+	chest    *Chest
+	overlay  int
+	wireFrom Structure
+	circuit  Structure
+
+	anchor    position
+	path      []position
+	blueprint *Blueprint
+	library   *BlueprintLibrary
+	share     string
+	err       error
+	overflow  int
+	routeFrom Transfer
+	route     []RouteStep
+
+	alert           int
+	alertsCollapsed bool
+
+	minimap      bool
+	legend       bool
+	viewFrom     position
+	viewTo       position
+	overviewFrom position
+
+	count int
+	jump  bool
+
+	tutorial *Tutorial
 }
 
 // GameWindow a Window that manages all the GameWidget-s
@@ -27,8 +80,10 @@ type GameWindow struct {
 
 	game    *Game
 	running bool
+	s       *state
 
 	mainWindow Window
+	help       *HelpWindow
 
 	widgets []GameWidget
 }
@@ -37,14 +92,17 @@ type GameWindow struct {
 func NewGameWindow(manager WindowManager) *GameWindow {
 	s := new(state)
 	s.st = make([]*Storage, 2)
+	s.library, s.err = LoadBlueprintLibrary(defaultBlueprintLibraryPath())
 
 	var w GameWindow
 	w.manager = manager
+	w.s = s
+	w.help = NewHelpWindow(manager)
 
 	var infoWidget InfoWidget
 	infoWidget.name = "Info"
 	infoWidget.width = 20
-	infoWidget.height = 10
+	infoWidget.height = 12
 	infoWidget.s = s
 
 	var gameMapWidget GameMapWidget
@@ -53,6 +111,10 @@ func NewGameWindow(manager WindowManager) *GameWindow {
 	gameMapWidget.maxViewY = 80
 	gameMapWidget.reservedX = infoWidget.width
 	gameMapWidget.s = s
+	gameMapWidget.manager = manager
+	gameMapWidget.statistics = NewStatisticsWindow(manager, &w)
+	gameMapWidget.help = w.help
+	gameMapWidget.gameWindow = &w
 
 	structureSelectorWidget := newStructureSelectorWidget()
 	structureSelectorWidget.name = "Structure"
@@ -85,12 +147,100 @@ func NewGameWindow(manager WindowManager) *GameWindow {
 	chestInventoryWidget.storageIndex = 1
 	chestInventoryWidget.s = s
 
+	scheduleWidget := newTrainScheduleWidget()
+	scheduleWidget.name = "Schedule"
+	scheduleWidget.width = 20
+	scheduleWidget.height = 8
+	scheduleWidget.offsetY = infoWidget.height + 1
+	scheduleWidget.s = s
+
+	requestWidget := newRequestWidget()
+	requestWidget.name = "Requests"
+	requestWidget.width = 20
+	requestWidget.height = 8
+	requestWidget.offsetY = infoWidget.height + 1
+	requestWidget.s = s
+
+	circuitWidget := newCircuitWidget()
+	circuitWidget.name = "Circuit"
+	circuitWidget.width = 20
+	circuitWidget.height = 6
+	circuitWidget.offsetY = infoWidget.height + 1
+	circuitWidget.s = s
+
+	signalsWidget := newSignalsWidget()
+	signalsWidget.name = "Signals"
+	signalsWidget.width = 20
+	signalsWidget.height = 7
+	signalsWidget.offsetY = infoWidget.height + 1
+	signalsWidget.s = s
+
+	blueprintWidget := newBlueprintLibraryWidget()
+	blueprintWidget.name = "Blueprints"
+	blueprintWidget.width = 20
+	blueprintWidget.height = 8
+	blueprintWidget.offsetY = infoWidget.height + 1
+	blueprintWidget.s = s
+
+	exportWidget := newBlueprintShareWidget(false)
+	exportWidget.name = "Export"
+	exportWidget.reservedX = infoWidget.width
+	exportWidget.height = 5
+	exportWidget.s = s
+
+	importWidget := newBlueprintShareWidget(true)
+	importWidget.name = "Import"
+	importWidget.reservedX = infoWidget.width
+	importWidget.height = 5
+	importWidget.s = s
+
+	deconstructWidget := newDeconstructWidget()
+	deconstructWidget.name = "Deconstruct"
+	deconstructWidget.width = 20
+	deconstructWidget.height = 8
+	deconstructWidget.offsetY = infoWidget.height + 1
+	deconstructWidget.s = s
+
+	alertsWidget := newAlertsWidget()
+	alertsWidget.name = "Alerts"
+	alertsWidget.width = 20
+	alertsWidget.height = 7
+	alertsWidget.offsetY = infoWidget.height + 1
+	alertsWidget.s = s
+
+	minimapWidget := newMinimapWidget()
+	minimapWidget.name = "Minimap"
+	minimapWidget.width = 20
+	minimapWidget.s = s
+
+	legendWidget := newLegendWidget()
+	legendWidget.name = "Legend"
+	legendWidget.s = s
+
+	tutorialWidget := newTutorialWidget()
+	tutorialWidget.name = "Tutorial"
+	tutorialWidget.reservedX = infoWidget.width
+	tutorialWidget.height = 5
+	tutorialWidget.s = s
+
 	w.widgets = append(w.widgets, &gameMapWidget)
 	w.widgets = append(w.widgets, &infoWidget)
 	w.widgets = append(w.widgets, structureSelectorWidget)
 	w.widgets = append(w.widgets, inventoryWidget)
 	w.widgets = append(w.widgets, chestInventoryWidget)
 	w.widgets = append(w.widgets, recipeSelectorWidget)
+	w.widgets = append(w.widgets, scheduleWidget)
+	w.widgets = append(w.widgets, requestWidget)
+	w.widgets = append(w.widgets, circuitWidget)
+	w.widgets = append(w.widgets, signalsWidget)
+	w.widgets = append(w.widgets, blueprintWidget)
+	w.widgets = append(w.widgets, exportWidget)
+	w.widgets = append(w.widgets, importWidget)
+	w.widgets = append(w.widgets, deconstructWidget)
+	w.widgets = append(w.widgets, alertsWidget)
+	w.widgets = append(w.widgets, minimapWidget)
+	w.widgets = append(w.widgets, legendWidget)
+	w.widgets = append(w.widgets, tutorialWidget)
 
 	return &w
 }
@@ -115,6 +265,11 @@ func (w *GameWindow) SetGame(g *Game) {
 	}
 }
 
+// SetTutorial starts the Tutorial in the GameWindow, nil stops it
+func (w *GameWindow) SetTutorial(t *Tutorial) {
+	w.s.tutorial = t
+}
+
 // HasGame indicates if there is a Game associated with the GameWindow
 func (w *GameWindow) HasGame() bool {
 	return w.game != nil
@@ -133,6 +288,17 @@ func (w *GameWindow) Layout(g *gocui.Gui) error {
 		}
 
 		v.Title = "Gopher Industries"
+		if depleted := w.game.CountAlerts(AlertExtractorDepleted); depleted > 0 {
+			v.Title += fmt.Sprintf(" - ! %d depleted extractor(s)", depleted)
+		}
+		if w.s.overlay == overlayLogistics {
+			v.Title += fmt.Sprintf(" - %d deliveries", len(w.game.Deliveries()))
+		}
+		if w.s.overlay == overlayHeatmap {
+			// the colors of the statuses depend on the color configuration
+			v.Title += " - heatmap: " + strings.Join(statusNames, ", ")
+		}
+		v.Title += " - help: " + GlobalKeymap.Help(CommandHelp)
 	} else {
 		return err
 	}
@@ -168,6 +334,12 @@ func (w *InfoWidget) Layout(g *gocui.Gui) error {
 		}
 
 		v.Title = fmt.Sprintf("%s - %d:%d", w.name, cursorX+1, displayY)
+		if w.s.count > 0 {
+			v.Title += fmt.Sprintf(" %d", w.s.count)
+		}
+		if w.s.jump {
+			v.Title += " jump"
+		}
 	}
 
 	v.Clear()
@@ -175,8 +347,8 @@ func (w *InfoWidget) Layout(g *gocui.Gui) error {
 	if w.s.state == stateStructureSelect {
 		fmt.Fprintf(v, "Choose structure\n")
 		fmt.Fprint(v, "navigate: ↑↓\n")
-		fmt.Fprint(v, "cancel  : c\n")
-		fmt.Fprint(v, "select  : ˽")
+		printHelp(v, "cancel  ", CommandCancel)
+		printHelp(v, "select  ", CommandConfirm)
 
 		return nil
 	}
@@ -192,15 +364,154 @@ func (w *InfoWidget) Layout(g *gocui.Gui) error {
 			structureName = "extractor"
 		case *Splitter:
 			structureName = "splitter"
+		case *Factory:
+			structureName = "factory"
+		case *Underground:
+			structureName = "underground"
+		case *OffshorePump:
+			structureName = "pump"
+		case *Pipe:
+			structureName = "pipe"
+		case *Tank:
+			structureName = "tank"
+		case *Rail:
+			structureName = "rail"
+		case *Station:
+			structureName = "station"
+		case *LogisticHub:
+			structureName = "hub"
+		case *ArithmeticCombinator:
+			structureName = "arithmetic"
+		case *DeciderCombinator:
+			structureName = "decider"
 		default:
 			structureName = "unknown"
 		}
 
 		fmt.Fprintf(v, "Placing: %s\n", structureName)
-		fmt.Fprint(v, "rotate: qr\n")
-		fmt.Fprint(v, "cancel: c\n")
-		fmt.Fprint(v, "place : ˽\n")
-		fmt.Fprint(v, "move  : ↑←↓→\n")
+		printHelp(v, "rotate", CommandRotateLeft, CommandRotateRight)
+		printHelp(v, "cancel", CommandCancel)
+		printHelp(v, "place ", CommandConfirm)
+		printHelp(v, "move  ", CommandUp, CommandLeft, CommandDown, CommandRight)
+
+		return nil
+	}
+
+	if w.s.state == stateBlueprintSelect {
+		y0, x0, y1, x1 := selectionBounds(w.s.anchor, position{x: cursorX, y: cursorY})
+		fmt.Fprintf(v, "Select area\n")
+		fmt.Fprintf(v, "%dx%d\n", x1-x0+1, y1-y0+1)
+		printHelp(v, "resize ", CommandUp, CommandLeft, CommandDown, CommandRight)
+		printHelp(v, "cancel ", CommandCancel)
+		printHelp(v, "capture", CommandConfirm)
+
+		return nil
+	}
+
+	if w.s.state == stateOverview {
+		fmt.Fprintf(v, "Overview\n")
+		printHelp(v, "pan   ", CommandUp, CommandLeft, CommandDown, CommandRight)
+		printHelp(v, "cancel", CommandCancel)
+		printHelp(v, "jump  ", CommandConfirm)
+
+		return nil
+	}
+
+	if w.s.state == stateRouteTarget {
+		fmt.Fprintf(v, "Route belts\n")
+		fmt.Fprint(v, "Choose target\n")
+		printHelp(v, "move  ", CommandUp, CommandLeft, CommandDown, CommandRight)
+		printHelp(v, "cancel", CommandCancel)
+		printHelp(v, "route ", CommandConfirm)
+
+		return nil
+	}
+
+	if w.s.state == stateRouteConfirm {
+		fmt.Fprintf(v, "Route belts\n")
+		for product, count := range RouteRequirements(w.s.route) {
+			fmt.Fprintf(v, "%3d/%3d %s\n", w.game.inventory.objects[product], count, product.name)
+		}
+		printHelp(v, "cancel", CommandCancel)
+		printHelp(v, "build ", CommandConfirm)
+
+		return nil
+	}
+
+	if w.s.state == stateDeconstructSelect {
+		y0, x0, y1, x1 := selectionBounds(w.s.anchor, position{x: cursorX, y: cursorY})
+		fmt.Fprintf(v, "Deconstruct area\n")
+		fmt.Fprintf(v, "%dx%d\n", x1-x0+1, y1-y0+1)
+		printHelp(v, "resize ", CommandUp, CommandLeft, CommandDown, CommandRight)
+		printHelp(v, "cancel ", CommandCancel)
+		printHelp(v, "select ", CommandConfirm)
+
+		return nil
+	}
+
+	if w.s.state == stateDeconstructConfirm {
+		fmt.Fprintf(v, "Deconstruct area\n")
+		printHelp(v, "confirm", CommandAccept)
+		printHelp(v, "policy ", CommandPolicy)
+		printHelp(v, "cancel ", CommandDecline)
+
+		return nil
+	}
+
+	if w.s.state == stateBeltDrag {
+		belts := w.game.inventory.objects[GlobalProductFactory.GetProduct(ProductStructureBelt)]
+		fmt.Fprintf(v, "Drag belts\n")
+		fmt.Fprintf(v, "Length: %d/%d\n", len(w.s.path), belts)
+		printHelp(v, "path  ", CommandUp, CommandLeft, CommandDown, CommandRight)
+		printHelp(v, "cancel", CommandCancel)
+		printHelp(v, "place ", CommandConfirm)
+
+		return nil
+	}
+
+	if w.s.state == stateBlueprintGhost {
+		fmt.Fprintf(v, "Pasting: %s\n", w.s.blueprint.Name())
+		for product, count := range w.s.blueprint.Requirements() {
+			fmt.Fprintf(v, "%3d/%3d %s\n", w.game.inventory.objects[product], count, product.name)
+		}
+		printHelp(v, "rotate", CommandRotateLeft, CommandRotateRight)
+		printHelp(v, "cancel", CommandCancel)
+		printHelp(v, "place ", CommandConfirm)
+
+		return nil
+	}
+
+	if w.s.state == stateBlueprintLibrary {
+		fmt.Fprintf(v, "Blueprints\n")
+		fmt.Fprint(v, "navigate: ↑↓\n")
+		printHelp(v, "delete  ", CommandRemove)
+		printHelp(v, "export  ", CommandExport)
+		printHelp(v, "import  ", CommandImport)
+		printHelp(v, "close   ", CommandCancel)
+		printHelp(v, "select  ", CommandConfirm)
+		if w.s.err != nil {
+			fmt.Fprintf(v, "\033[31;1m%s\033[0m\n", w.s.err)
+		}
+
+		return nil
+	}
+
+	if w.s.state == stateBlueprintExport {
+		fmt.Fprintf(v, "Export blueprint\n")
+		fmt.Fprint(v, "copy the text below\n")
+		printHelp(v, "close   ", CommandCancel)
+
+		return nil
+	}
+
+	if w.s.state == stateBlueprintImport {
+		fmt.Fprintf(v, "Import blueprint\n")
+		fmt.Fprint(v, "paste the text below\n")
+		fmt.Fprint(v, "add     : ↵\n")
+		fmt.Fprint(v, "close   : empty ↵\n")
+		if w.s.err != nil {
+			fmt.Fprintf(v, "\033[31;1m%s\033[0m\n", w.s.err)
+		}
 
 		return nil
 	}
@@ -213,10 +524,53 @@ func (w *InfoWidget) Layout(g *gocui.Gui) error {
 		}
 
 		fmt.Fprint(v, "navigate: ↑↓\n")
-		fmt.Fprint(v, "cancel  : c\n")
-		fmt.Fprint(v, "switch  : t\n")
-		fmt.Fprint(v, "delete  : d\n")
-		fmt.Fprint(v, "transfer: ˽\n")
+		printHelp(v, "cancel  ", CommandCancel)
+		printHelp(v, "switch  ", CommandTransfer)
+		printHelp(v, "delete  ", CommandDelete)
+		printHelp(v, "transfer", CommandConfirm)
+
+		return nil
+	}
+
+	if w.s.state == stateEditSchedule {
+		fmt.Fprintf(v, "Edit schedule\n")
+		fmt.Fprint(v, "navigate: ↑↓\n")
+		fmt.Fprint(v, "station : ←→\n")
+		printHelp(v, "add     ", CommandAdd)
+		printHelp(v, "load    ", CommandLoad)
+		printHelp(v, "delete  ", CommandDelete)
+		printHelp(v, "close   ", CommandCancel)
+
+		return nil
+	}
+
+	if w.s.state == stateEditCircuit {
+		fmt.Fprintf(v, "Edit circuit\n")
+		fmt.Fprint(v, "navigate: ↑↓\n")
+		fmt.Fprint(v, "change  : ←→\n")
+		printHelp(v, "by ten  ", CommandDecrease, CommandIncrease)
+		printHelp(v, "remove  ", CommandRemove)
+		printHelp(v, "close   ", CommandCancel)
+
+		return nil
+	}
+
+	if w.s.state == stateSetRequests {
+		fmt.Fprintf(v, "Set requests\n")
+		fmt.Fprint(v, "navigate: ↑↓\n")
+		fmt.Fprint(v, "change  : ←→\n")
+		printHelp(v, "clear   ", CommandRemove)
+		printHelp(v, "close   ", CommandCancel)
+
+		return nil
+	}
+
+	if train := w.game.GetTrainAt(cursorY, cursorX); train != nil {
+		fmt.Fprintf(v, "%s\n", train.Name())
+		fmt.Fprintf(v, "Cargo: %d/%d\n", train.cargo.Size(), train.cargo.Capacity())
+		printHelp(v, "navigate", CommandUp, CommandLeft, CommandDown, CommandRight)
+		printHelp(v, "schedule", CommandSchedule)
+		printHelp(v, "delete  ", CommandDelete)
 
 		return nil
 	}
@@ -225,15 +579,25 @@ func (w *InfoWidget) Layout(g *gocui.Gui) error {
 	if structure == nil {
 		switch r := w.game.WorldMap[cursorY][cursorX].(type) {
 		case *RawResource:
-			if r.amount > 0 {
+			if r.resource == ResourceWater {
+				fmt.Fprint(v, "Water\n")
+			} else if r.amount > 0 {
 				fmt.Fprintf(v, "Resource %d\n", r.amount)
 			} else {
 				fmt.Fprint(v, "Empty tile\n")
 			}
 		}
 
-		fmt.Fprint(v, "navigate: ↑←↓→\n")
-		fmt.Fprint(v, "add     : a\n")
+		printHelp(v, "navigate", CommandUp, CommandLeft, CommandDown, CommandRight)
+		printHelp(v, "add     ", CommandAdd)
+		printHelp(v, "belts   ", CommandBelts)
+		printHelp(v, "copy    ", CommandCopy)
+		printHelp(v, "clear   ", CommandClear)
+		printHelp(v, "library ", CommandLibrary)
+		printHelp(v, "undo    ", CommandUndo, CommandRedo)
+		printHelp(v, "stats   ", CommandStatistics)
+		printHelp(v, "alerts  ", CommandAlerts, CommandNextAlert)
+		printHelp(v, "map     ", CommandMinimap, CommandOverview, CommandLegend)
 
 		return nil
 	}
@@ -248,20 +612,124 @@ func (w *InfoWidget) Layout(g *gocui.Gui) error {
 		structureName = "extractor"
 	case *Splitter:
 		structureName = "splitter"
+	case *Factory:
+		structureName = "factory"
+	case *Underground:
+		structureName = "underground"
+	case *OffshorePump:
+		structureName = "pump"
+	case *Pipe:
+		structureName = "pipe"
+	case *Tank:
+		structureName = "tank"
+	case *Rail:
+		structureName = "rail"
+	case *Station:
+		structureName = "station"
+	case *LogisticHub:
+		structureName = "hub"
+	case *ArithmeticCombinator:
+		structureName = "arithmetic"
+	case *DeciderCombinator:
+		structureName = "decider"
 	default:
 		structureName = "unknown"
 	}
 	fmt.Fprintf(v, "Structure: %s\n", structureName)
-	fmt.Fprint(v, "navigate: ↑←↓→\n")
-	fmt.Fprint(v, "delete  : d\n")
-	fmt.Fprint(v, "add     : a\n")
-	if structureName == "chest" {
-		fmt.Fprint(v, "transfer: t\n")
+
+	if status, ok := w.game.StructureStatus(structure); ok && w.s.overlay == overlayHeatmap {
+		fmt.Fprintf(v, "Status: %s\n", statusNames[status])
+	}
+
+	if w.s.wireFrom != nil {
+		fmt.Fprint(v, "Wiring...\n")
+	}
+
+	if condition := w.game.CircuitCondition(structure); condition != nil {
+		status := "off"
+		if w.game.circuitEnabled(structure) {
+			status = "on"
+		}
+		fmt.Fprintf(v, "If %s: %s\n", condition, status)
+	}
+
+	switch e := structure.(type) {
+	case *Extractor:
+		resourceName := "any"
+		if e.Filter() != -1 {
+			resourceName = GlobalProductFactory.GetProduct(e.Filter()).name
+		}
+		fmt.Fprintf(v, "Mining: %s\n", resourceName)
+
+		if e.Depleted() {
+			fmt.Fprint(v, "\033[31;1mDepleted\033[0m\n")
+		} else {
+			eta := time.Duration(e.TicksToDepletion()) * TickDuration
+			fmt.Fprintf(v, "Left: %d\n", e.Remaining())
+			fmt.Fprintf(v, "ETA : %s\n", eta.Round(time.Second))
+		}
+	case *Station:
+		fmt.Fprintf(v, "%s\n", e.Name())
+	case *Chest:
+		switch e.kind {
+		case ChestKindProvider:
+			fmt.Fprint(v, "Logistic: provider\n")
+		case ChestKindRequester:
+			fmt.Fprintf(v, "Logistic: requester\n")
+		case ChestKindStorage:
+			fmt.Fprint(v, "Logistic: storage\n")
+		}
+	case *LogisticHub:
+		fmt.Fprintf(v, "Bots: %d/%d busy\n", w.game.busyBots(e), e.bots)
+	case *ArithmeticCombinator:
+		fmt.Fprintf(v, "%s\n", e)
+	case *DeciderCombinator:
+		fmt.Fprintf(v, "If %s\n", e.condition)
+	case FluidStructure:
+		box := e.FluidBox()
+		if box.Capacity() > 0 {
+			fluidName := "empty"
+			if box.Fluid() != nil {
+				fluidName = box.Fluid().name
+			}
+			fmt.Fprintf(v, "Fluid: %s\n", fluidName)
+			fmt.Fprintf(v, "%d/%d\n", box.Amount(), box.Capacity())
+		}
+	}
+
+	printHelp(v, "navigate", CommandUp, CommandLeft, CommandDown, CommandRight)
+	printHelp(v, "delete  ", CommandDelete)
+	printHelp(v, "add     ", CommandAdd)
+	printHelp(v, "copy    ", CommandCopy)
+	printHelp(v, "clear   ", CommandClear)
+	if len(structure.Outputs()) != 0 {
+		printHelp(v, "route   ", CommandRoute)
+	}
+	if _, ok := structure.(*Chest); ok {
+		printHelp(v, "transfer", CommandTransfer)
+	}
+	if structureName == "extractor" {
+		printHelp(v, "resource", CommandFilter)
+	}
+	if structureName == "station" {
+		printHelp(v, "train   ", CommandTrain)
+	}
+	if c, ok := structure.(*Chest); ok && c.kind == ChestKindRequester {
+		printHelp(v, "requests", CommandRequests)
+	}
+	printHelp(v, "wire    ", CommandWire)
+	if circuitConfigurable(structure) {
+		printHelp(v, "circuit ", CommandCircuit)
 	}
 
 	return nil
 }
 
+// printHelp prints the label followed by the symbols of the keys bound to the commands
+func printHelp(v *gocui.View, label string, commands ...Command) {
+	fmt.Fprintf(v, "%s: %s\n", label, GlobalKeymap.Help(commands...))
+}
+
 // SetGame sets the Game associated with InfoWidget
 func (w *InfoWidget) SetGame(game *Game) {
 	w.game = game
@@ -275,13 +743,29 @@ type GameMapWidget struct {
 
 	game *Game
 
+	manager    WindowManager
+	statistics Window
+	help       *HelpWindow
+	gameWindow Window
+
 	offsetX, offsetY int
 	s                *state
+
+	overviewFirst position
 }
 
 // SetGame sets the Game associated with the GameMapWidget
 func (w *GameMapWidget) SetGame(game *Game) {
+	w.s.state = stateNavigate
 	w.s.ghost = nil
+	w.s.train = nil
+	w.s.chest = nil
+	w.s.wireFrom = nil
+	w.s.circuit = nil
+	w.s.blueprint = nil
+	w.s.path = nil
+	w.s.route = nil
+	w.s.tutorial = nil
 	w.game = game
 }
 
@@ -321,7 +805,9 @@ func (w *GameMapWidget) Layout(g *gocui.Gui) error {
 		}
 	}
 
-	if w.s.state == stateNavigate || w.s.state == stateStructureGhost {
+	switch w.s.state {
+	case stateNavigate, stateStructureGhost, stateBlueprintSelect, stateBlueprintGhost, stateBeltDrag, stateDeconstructSelect,
+		stateRouteTarget, stateRouteConfirm, stateOverview:
 		if _, err := g.SetCurrentView(w.name); err != nil {
 			return err
 		}
@@ -334,6 +820,16 @@ func (w *GameMapWidget) Layout(g *gocui.Gui) error {
 	v.Clear()
 	v.Frame = false
 
+	if w.s.state == stateOverview {
+		w.layoutOverview(v)
+		return nil
+	}
+
+	// keep the cursor visible when it was moved without scrolling the map
+	cx, cy := w.game.GetCursor()
+	w.offsetX = clamp(w.offsetX, cx-(maxX-2)+1, cx)
+	w.offsetY = clamp(w.offsetY, cy-(maxY-2)+1, cy)
+
 	worldMaxY := w.offsetY + maxY - 2
 	worldMaxX := w.offsetX + maxX - 2
 
@@ -347,7 +843,6 @@ func (w *GameMapWidget) Layout(g *gocui.Gui) error {
 		w.offsetX += adjustX
 	}
 
-	ghostMap := make(map[Tile]Tile)
 	selectedMap := make(map[Tile]Tile)
 
 	ghostHeight := -1
@@ -363,30 +858,20 @@ func (w *GameMapWidget) Layout(g *gocui.Gui) error {
 		ghostHeight = len(ghost)
 		ghostWidth = len(ghost[0])
 
-		maxGhostX, maxGhostY := cursorX+ghostWidth, cursorY+ghostHeight
-
-		if !w.game.WithinBounds(maxGhostX, maxGhostY) {
+		if !w.game.CanPlaceStructure(cursorY, cursorX, w.s.ghost) {
 			mode = DisplayModeGhostInvalid
-		} else {
-			// check overlap
-			for i, tileStructures := range ghost {
-				for j, tileStructure := range tileStructures {
-					if tileStructure == nil {
-						continue
-					}
-					ghostMap[tileStructure] = tileStructure
-
-					switch w.game.WorldMap[i+cursorY][j+cursorX].(type) {
-					case StructureTile:
-						mode = DisplayModeGhostInvalid
-						break
-					}
-				}
-				if mode == DisplayModeGhostInvalid {
-					break
-				}
+		}
+	} else if w.s.state == stateBlueprintSelect || w.s.state == stateDeconstructSelect || w.s.state == stateDeconstructConfirm {
+		y0, x0, y1, x1 := selectionBounds(w.s.anchor, position{x: cursorX, y: cursorY})
+		for i := y0; i <= y1; i++ {
+			for j := x0; j <= x1; j++ {
+				selectedMap[w.game.WorldMap[i][j]] = w.game.WorldMap[i][j]
 			}
 		}
+	} else if train := w.game.GetTrainAt(cursorY, cursorX); train != nil {
+		for _, tile := range train.Tiles() {
+			selectedMap[tile] = tile
+		}
 	} else {
 		switch selectTile := w.game.WorldMap[cursorY][cursorX].(type) {
 		case StructureTile:
@@ -402,6 +887,97 @@ func (w *GameMapWidget) Layout(g *gocui.Gui) error {
 		}
 	}
 
+	ghostTiles := make(map[position]string)
+	if w.s.blueprint != nil {
+		if !w.game.CanPlaceBlueprint(cursorY, cursorX, w.s.blueprint) {
+			mode = DisplayModeGhostInvalid
+		}
+
+		for _, entry := range w.s.blueprint.Entries() {
+			for i, tiles := range entry.structure.Tiles() {
+				for j, tile := range tiles {
+					if tile != nil {
+						ghostTiles[position{x: cursorX + entry.x + j, y: cursorY + entry.y + i}] = tile.Display(mode)
+					}
+				}
+			}
+		}
+	}
+
+	if w.s.route != nil {
+		routeMode := DisplayModeGhostValid
+		if !w.game.CanPlaceRoute(w.s.route) {
+			routeMode = DisplayModeGhostInvalid
+		}
+
+		for _, step := range w.s.route {
+			for i, tiles := range step.s.Tiles() {
+				for j, tile := range tiles {
+					if tile != nil {
+						ghostTiles[position{x: step.pos.x + j, y: step.pos.y + i}] = tile.Display(routeMode)
+					}
+				}
+			}
+		}
+	}
+
+	if w.s.state == stateBeltDrag {
+		available := w.game.inventory.objects[GlobalProductFactory.GetProduct(ProductStructureBelt)]
+		for i, belt := range BeltLine(w.s.path) {
+			p := w.s.path[i]
+			beltMode := DisplayModeGhostValid
+			if !w.game.CanPlaceStructure(p.y, p.x, belt) || available == 0 {
+				beltMode = DisplayModeGhostInvalid
+			} else {
+				available--
+			}
+
+			ghostTiles[p] = belt.Tiles()[0][0].Display(beltMode)
+		}
+	}
+
+	trainTiles := make(map[position]Tile)
+	for _, train := range w.game.trains {
+		for p, tile := range train.Tiles() {
+			trainTiles[p] = tile
+		}
+	}
+
+	overlayTiles := make(map[position]Tile)
+	if w.s.overlay == overlayLogistics {
+		for _, delivery := range w.game.Deliveries() {
+			overlayTiles[delivery.Position()] = delivery.Tile()
+		}
+	}
+
+	wiredMap := make(map[Tile]Tile)
+	if w.s.overlay == overlayCircuit {
+		for structure := range w.game.wires {
+			for _, tiles := range structure.Tiles() {
+				for _, tile := range tiles {
+					wiredMap[tile] = tile
+				}
+			}
+		}
+	}
+
+	statusMap := make(map[Tile]DisplayMode)
+	if w.s.overlay == overlayHeatmap {
+		for structure := range w.game.statuses {
+			status, _ := w.game.StructureStatus(structure)
+			for _, tiles := range structure.Tiles() {
+				for _, tile := range tiles {
+					if tile != nil {
+						statusMap[tile] = statusDisplayModes[status]
+					}
+				}
+			}
+		}
+	}
+
+	w.s.viewFrom = position{x: w.offsetX, y: w.offsetY}
+	w.s.viewTo = position{x: worldMaxX - 1, y: worldMaxY - 1}
+
 	for i := w.offsetY; i < worldMaxY; i++ {
 		for j := w.offsetX; j < worldMaxX; j++ {
 			if w.s.ghost != nil &&
@@ -412,9 +988,23 @@ func (w *GameMapWidget) Layout(g *gocui.Gui) error {
 				ghost[i-cursorY][j-cursorX] != nil {
 
 				fmt.Fprintf(v, "%s", ghost[i-cursorY][j-cursorX].Display(mode))
+			} else if tile, ok := ghostTiles[position{x: j, y: i}]; ok {
+				fmt.Fprintf(v, "%s", tile)
+			} else if tile, ok := overlayTiles[position{x: j, y: i}]; ok {
+				fmt.Fprintf(v, "%s", tile.Display(DisplayModeGhostValid))
+			} else if tile, ok := trainTiles[position{x: j, y: i}]; ok {
+				if _, ok := selectedMap[tile]; ok {
+					fmt.Fprintf(v, "%s", tile.Display(DisplayModeMapSelected))
+				} else {
+					fmt.Fprintf(v, "%s", tile.Display(DisplayModeMap))
+				}
 			} else {
 				if _, ok := selectedMap[w.game.WorldMap[i][j]]; ok {
 					fmt.Fprintf(v, "%s", w.game.WorldMap[i][j].Display(DisplayModeMapSelected))
+				} else if _, ok := wiredMap[w.game.WorldMap[i][j]]; ok {
+					fmt.Fprintf(v, "%s", w.game.WorldMap[i][j].Display(DisplayModeGhostValid))
+				} else if statusMode, ok := statusMap[w.game.WorldMap[i][j]]; ok {
+					fmt.Fprintf(v, "%s", w.game.WorldMap[i][j].Display(statusMode))
 				} else {
 					fmt.Fprintf(v, "%s", w.game.WorldMap[i][j].Display(DisplayModeMap))
 				}
@@ -427,24 +1017,95 @@ func (w *GameMapWidget) Layout(g *gocui.Gui) error {
 	return nil
 }
 
-func (w *GameMapWidget) initBindings(g *gocui.Gui) error {
-	if err := g.SetKeybinding(w.name, gocui.KeyArrowDown, gocui.ModNone,
-		w.move(0, 1)); err != nil {
-		return err
+// layoutOverview displays the zoomed-out map centered on the cursor, marking the cursor and the last viewport
+func (w *GameMapWidget) layoutOverview(v *gocui.View) {
+	width, height := v.Size()
+	cursorX, cursorY := w.game.GetCursor()
+
+	rows := (len(w.game.WorldMap) + OverviewCellTiles - 1) / OverviewCellTiles
+	columns := (len(w.game.WorldMap[0]) + OverviewCellTiles - 1) / OverviewCellTiles
+
+	firstRow := clamp(cursorY/OverviewCellTiles-height/2, 0, rows-height)
+	firstColumn := clamp(cursorX/OverviewCellTiles-width/2, 0, columns-width)
+	w.overviewFirst = position{x: firstColumn, y: firstRow}
+
+	for i := firstRow; i < rows && i < firstRow+height; i++ {
+		for j := firstColumn; j < columns && j < firstColumn+width; j++ {
+			colorMode := 1
+			if i == cursorY/OverviewCellTiles && j == cursorX/OverviewCellTiles {
+				colorMode = 7
+			} else if i >= w.s.viewFrom.y/OverviewCellTiles && i <= w.s.viewTo.y/OverviewCellTiles &&
+				j >= w.s.viewFrom.x/OverviewCellTiles && j <= w.s.viewTo.x/OverviewCellTiles {
+				colorMode = 4
+			}
+
+			glyph, kind := w.game.overviewCell(i, j)
+			fmt.Fprint(v, displayMapCell(glyph, kind, colorMode))
+		}
+		fmt.Fprintln(v, "")
 	}
-	if err := g.SetKeybinding(w.name, gocui.KeyArrowUp, gocui.ModNone,
-		w.move(0, -1)); err != nil {
-		return err
+}
+
+// clamp limits the value to the interval, the lower limit wins if the interval is empty
+func clamp(value, low, high int) int {
+	if value > high {
+		value = high
 	}
-	if err := g.SetKeybinding(w.name, gocui.KeyArrowLeft, gocui.ModNone,
-		w.move(-1, 0)); err != nil {
-		return err
+	if value < low {
+		value = low
 	}
-	if err := g.SetKeybinding(w.name, gocui.KeyArrowRight, gocui.ModNone,
-		w.move(1, 0)); err != nil {
+
+	return value
+}
+
+func (w *GameMapWidget) initBindings(g *gocui.Gui) error {
+	tile := func(v *gocui.View) int {
+		return 1
+	}
+	screenWidth := func(v *gocui.View) int {
+		width, _ := v.Size()
+		return width
+	}
+	screenHeight := func(v *gocui.View) int {
+		_, height := v.Size()
+		return height
+	}
+
+	motions := []struct {
+		command Command
+		dx, dy  int
+		steps   func(v *gocui.View) int
+	}{
+		{CommandDown, 0, 1, tile},
+		{CommandUp, 0, -1, tile},
+		{CommandLeft, -1, 0, tile},
+		{CommandRight, 1, 0, tile},
+		{CommandScreenDown, 0, 1, screenHeight},
+		{CommandScreenUp, 0, -1, screenHeight},
+		{CommandScreenLeft, -1, 0, screenWidth},
+		{CommandScreenRight, 1, 0, screenWidth},
+	}
+	for _, m := range motions {
+		if err := GlobalKeymap.SetKeybinding(g, w.name, m.command,
+			w.motion(m.dx, m.dy, m.steps)); err != nil {
+			return err
+		}
+	}
+	if err := GlobalKeymap.SetKeybinding(g, w.name, CommandJump,
+		func(g *gocui.Gui, v *gocui.View) error {
+			w.s.jump = !w.s.jump
+
+			return nil
+		}); err != nil {
 		return err
 	}
-	if err := g.SetKeybinding(w.name, 'a', gocui.ModNone,
+	for digit := '0'; digit <= '9'; digit++ {
+		if err := g.SetKeybinding(w.name, digit, gocui.ModNone,
+			w.countDigit(int(digit-'0'))); err != nil {
+			return err
+		}
+	}
+	if err := w.bind(g, CommandAdd,
 		func(g *gocui.Gui, v *gocui.View) error {
 			if w.s.state == stateNavigate {
 				w.s.state = stateStructureSelect
@@ -454,7 +1115,7 @@ func (w *GameMapWidget) initBindings(g *gocui.Gui) error {
 		}); err != nil {
 		return err
 	}
-	if err := g.SetKeybinding(w.name, 't', gocui.ModNone,
+	if err := w.bind(g, CommandTransfer,
 		func(g *gocui.Gui, v *gocui.View) error {
 			if w.s.state != stateNavigate {
 				return nil
@@ -473,127 +1134,1939 @@ func (w *GameMapWidget) initBindings(g *gocui.Gui) error {
 		}); err != nil {
 		return err
 	}
-	if err := g.SetKeybinding(w.name, 'c', gocui.ModNone,
+	if err := w.bind(g, CommandFilter,
 		func(g *gocui.Gui, v *gocui.View) error {
-			if w.s.state != stateStructureGhost {
+			if w.s.state != stateNavigate {
+				return nil
+			}
+
+			x, y := w.game.GetCursor()
+			structure, _, _ := w.game.GetStructureAt(y, x)
+			switch e := structure.(type) {
+			case *Extractor:
+				e.NextFilter()
+			}
+
+			return nil
+		}); err != nil {
+		return err
+	}
+	if err := w.bind(g, CommandCancel,
+		func(g *gocui.Gui, v *gocui.View) error {
+			switch w.s.state {
+			case stateStructureGhost, stateBlueprintSelect, stateBlueprintGhost, stateBeltDrag, stateDeconstructSelect,
+				stateRouteTarget, stateRouteConfirm:
+			case stateOverview:
+				// return to the location the overview was opened from
+				x, y := w.game.GetCursor()
+				w.s.state = stateNavigate
+
+				return w.move(w.s.overviewFrom.x-x, w.s.overviewFrom.y-y)(g, v)
+			default:
 				return nil
 			}
 
 			w.s.state = stateNavigate
 			w.s.ghost = nil
+			w.s.blueprint = nil
+			w.s.path = nil
+			w.s.route = nil
 
 			return nil
 		}); err != nil {
 		return err
 	}
-	if err := g.SetKeybinding(w.name, 'd', gocui.ModNone,
+	if err := w.bind(g, CommandRequests,
 		func(g *gocui.Gui, v *gocui.View) error {
 			if w.s.state != stateNavigate {
 				return nil
 			}
 
 			x, y := w.game.GetCursor()
-			s := w.game.RemoveStructure(y, x)
-			if s != nil {
-				p := GlobalProductFactory.GetProduct(s.GetCode())
-				w.game.inventory.Add(p, 1)
+			structure, _, _ := w.game.GetStructureAt(y, x)
+			if c, ok := structure.(*Chest); ok && c.kind == ChestKindRequester {
+				w.s.chest = c
+				w.s.state = stateSetRequests
 			}
 
 			return nil
 		}); err != nil {
 		return err
 	}
-	if err := g.SetKeybinding(w.name, 'e', gocui.ModNone,
+	if err := w.bind(g, CommandOverlay,
 		func(g *gocui.Gui, v *gocui.View) error {
-			if w.s.state != stateStructureGhost {
-				return nil
-			}
+			w.s.overlay = (w.s.overlay + 1) % overlayCount
 
-			if w.s.ghost != nil {
-				w.s.ghost.RotateRight()
-			}
 			return nil
 		}); err != nil {
 		return err
 	}
-	if err := g.SetKeybinding(w.name, 'q', gocui.ModNone,
+	if err := w.bind(g, CommandWire,
 		func(g *gocui.Gui, v *gocui.View) error {
-			if w.s.state != stateStructureGhost {
+			if w.s.state != stateNavigate {
 				return nil
 			}
 
-			if w.s.ghost != nil {
-				w.s.ghost.RotateLeft()
+			x, y := w.game.GetCursor()
+			structure, _, _ := w.game.GetStructureAt(y, x)
+			if structure == nil {
+				w.s.wireFrom = nil
+				return nil
+			}
+
+			if w.s.wireFrom == nil {
+				w.s.wireFrom = structure
+				return nil
 			}
+
+			w.game.ToggleWire(w.s.wireFrom, structure)
+			w.s.wireFrom = nil
+
 			return nil
 		}); err != nil {
 		return err
 	}
-	if err := g.SetKeybinding(w.name, gocui.KeySpace, gocui.ModNone,
+	if err := w.bind(g, CommandCircuit,
 		func(g *gocui.Gui, v *gocui.View) error {
-			if w.s.ghost != nil {
-				copy := w.s.ghost.CopyStructure()
-				x, y := w.game.GetCursor()
-				w.game.PlaceStructure(y, x, copy)
-
-				product := GlobalProductFactory.GetProduct(copy.GetCode())
-				w.game.inventory.Remove(product, 1)
+			if w.s.state != stateNavigate {
+				return nil
+			}
 
-				switch w.s.ghost.(type) {
-				case *Factory:
-					w.s.ghost = nil
-					w.s.state = stateSetRecipe
-				}
+			x, y := w.game.GetCursor()
+			structure, _, _ := w.game.GetStructureAt(y, x)
+			if !circuitConfigurable(structure) {
+				return nil
+			}
 
-				if w.s.state != stateSetRecipe {
-					count, isPresent := w.game.inventory.objects[product]
-					if !isPresent || count == 0 {
-						w.s.state = stateStructureSelect
-						w.s.ghost = nil
-						return nil
-					}
-				}
+			if _, ok := structure.(Combinator); !ok && w.game.CircuitCondition(structure) == nil {
+				w.game.SetCircuitCondition(structure, NewCircuitCondition())
 			}
+
+			w.s.circuit = structure
+			w.s.state = stateEditCircuit
+
 			return nil
 		}); err != nil {
 		return err
 	}
+	if err := w.bind(g, CommandTrain,
+		func(g *gocui.Gui, v *gocui.View) error {
+			if w.s.state != stateNavigate {
+				return nil
+			}
 
-	return nil
-}
+			w.game.AddTrain()
 
-func (w *GameMapWidget) move(dx, dy int) func(g *gocui.Gui, v *gocui.View) error {
+			return nil
+		}); err != nil {
+		return err
+	}
+	if err := w.bind(g, CommandSchedule,
+		func(g *gocui.Gui, v *gocui.View) error {
+			if w.s.state != stateNavigate {
+				return nil
+			}
+
+			x, y := w.game.GetCursor()
+			train := w.game.GetTrainAt(y, x)
+			if train == nil {
+				return nil
+			}
+
+			w.s.train = train
+			w.s.state = stateEditSchedule
+
+			return nil
+		}); err != nil {
+		return err
+	}
+	if err := w.bind(g, CommandDelete,
+		w.remove); err != nil {
+		return err
+	}
+	if err := w.bind(g, CommandRotateRight,
+		func(g *gocui.Gui, v *gocui.View) error {
+			if w.s.state == stateBlueprintGhost {
+				w.s.blueprint.RotateRight()
+				return nil
+			}
+
+			if w.s.state != stateStructureGhost {
+				return nil
+			}
+
+			if w.s.ghost != nil {
+				w.s.ghost.RotateRight()
+			}
+			return nil
+		}); err != nil {
+		return err
+	}
+	if err := w.bind(g, CommandRotateLeft,
+		func(g *gocui.Gui, v *gocui.View) error {
+			if w.s.state == stateBlueprintGhost {
+				w.s.blueprint.RotateLeft()
+				return nil
+			}
+
+			if w.s.state != stateStructureGhost {
+				return nil
+			}
+
+			if w.s.ghost != nil {
+				w.s.ghost.RotateLeft()
+			}
+			return nil
+		}); err != nil {
+		return err
+	}
+	if err := g.SetKeybinding(w.name, gocui.MouseLeft, gocui.ModNone,
+		w.click); err != nil {
+		return err
+	}
+	if err := g.SetKeybinding(w.name, gocui.MouseRight, gocui.ModNone,
+		w.rightClick); err != nil {
+		return err
+	}
+	if err := g.SetKeybinding(w.name, gocui.MouseWheelUp, gocui.ModNone,
+		w.scroll(-MouseWheelRows)); err != nil {
+		return err
+	}
+	if err := g.SetKeybinding(w.name, gocui.MouseWheelDown, gocui.ModNone,
+		w.scroll(MouseWheelRows)); err != nil {
+		return err
+	}
+	if err := w.bind(g, CommandMinimap,
+		func(g *gocui.Gui, v *gocui.View) error {
+			w.s.minimap = !w.s.minimap
+
+			return nil
+		}); err != nil {
+		return err
+	}
+	if err := w.bind(g, CommandLegend,
+		func(g *gocui.Gui, v *gocui.View) error {
+			w.s.legend = !w.s.legend
+
+			return nil
+		}); err != nil {
+		return err
+	}
+	if err := w.bind(g, CommandOverview,
+		func(g *gocui.Gui, v *gocui.View) error {
+			if w.s.state != stateNavigate {
+				return nil
+			}
+
+			x, y := w.game.GetCursor()
+			w.s.overviewFrom = position{x: x, y: y}
+			w.s.state = stateOverview
+
+			return nil
+		}); err != nil {
+		return err
+	}
+	if err := w.bind(g, CommandAlerts,
+		func(g *gocui.Gui, v *gocui.View) error {
+			w.s.alertsCollapsed = !w.s.alertsCollapsed
+
+			return nil
+		}); err != nil {
+		return err
+	}
+	if err := w.bind(g, CommandNextAlert,
+		func(g *gocui.Gui, v *gocui.View) error {
+			if w.s.state != stateNavigate {
+				return nil
+			}
+
+			alerts := w.game.Alerts()
+			// cycle from the newest alert with a source to the oldest
+			for i := 1; i <= len(alerts); i++ {
+				index := (w.s.alert - i + 2*len(alerts)) % len(alerts)
+				source, ok := alerts[index].Source()
+				if !ok {
+					continue
+				}
+
+				x, y := w.game.GetCursor()
+				w.s.alert = index
+
+				return w.move(source.x-x, source.y-y)(g, v)
+			}
+
+			return nil
+		}); err != nil {
+		return err
+	}
+	if err := w.bind(g, CommandStatistics,
+		func(g *gocui.Gui, v *gocui.View) error {
+			if w.s.state != stateNavigate {
+				return nil
+			}
+
+			w.manager.SetTopWindow(w.statistics)
+
+			return nil
+		}); err != nil {
+		return err
+	}
+	if err := w.bind(g, CommandHelp,
+		func(g *gocui.Gui, v *gocui.View) error {
+			if w.s.state != stateNavigate {
+				return nil
+			}
+
+			w.help.Show(w.gameWindow)
+
+			return nil
+		}); err != nil {
+		return err
+	}
+	if err := w.bind(g, CommandUndo,
+		func(g *gocui.Gui, v *gocui.View) error {
+			if w.s.state != stateNavigate {
+				return nil
+			}
+
+			w.game.Undo()
+			w.s.wireFrom = nil
+
+			return nil
+		}); err != nil {
+		return err
+	}
+	if err := w.bind(g, CommandRedo,
+		func(g *gocui.Gui, v *gocui.View) error {
+			if w.s.state != stateNavigate {
+				return nil
+			}
+
+			w.game.Redo()
+			w.s.wireFrom = nil
+
+			return nil
+		}); err != nil {
+		return err
+	}
+	if err := w.bind(g, CommandBelts,
+		func(g *gocui.Gui, v *gocui.View) error {
+			if w.s.state != stateNavigate {
+				return nil
+			}
+
+			x, y := w.game.GetCursor()
+			w.s.path = []position{{x: x, y: y}}
+			w.s.state = stateBeltDrag
+
+			return nil
+		}); err != nil {
+		return err
+	}
+	if err := w.bind(g, CommandClear,
+		func(g *gocui.Gui, v *gocui.View) error {
+			if w.s.state != stateNavigate {
+				return nil
+			}
+
+			x, y := w.game.GetCursor()
+			w.s.anchor = position{x: x, y: y}
+			w.s.state = stateDeconstructSelect
+
+			return nil
+		}); err != nil {
+		return err
+	}
+	if err := w.bind(g, CommandRoute,
+		func(g *gocui.Gui, v *gocui.View) error {
+			if w.s.state != stateNavigate {
+				return nil
+			}
+
+			x, y := w.game.GetCursor()
+			from, ok := w.game.FreeOutput(y, x)
+			if !ok {
+				return nil
+			}
+
+			w.s.routeFrom = from
+			w.s.state = stateRouteTarget
+
+			return nil
+		}); err != nil {
+		return err
+	}
+	if err := w.bind(g, CommandCopy,
+		func(g *gocui.Gui, v *gocui.View) error {
+			if w.s.state != stateNavigate {
+				return nil
+			}
+
+			x, y := w.game.GetCursor()
+			w.s.anchor = position{x: x, y: y}
+			w.s.state = stateBlueprintSelect
+
+			return nil
+		}); err != nil {
+		return err
+	}
+	if err := w.bind(g, CommandLibrary,
+		func(g *gocui.Gui, v *gocui.View) error {
+			if w.s.state != stateNavigate {
+				return nil
+			}
+
+			w.s.state = stateBlueprintLibrary
+
+			return nil
+		}); err != nil {
+		return err
+	}
+	if err := w.bind(g, CommandConfirm,
+		w.confirm); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// remove returns the Train or the Structure under the cursor to the inventory
+func (w *GameMapWidget) remove(g *gocui.Gui, v *gocui.View) error {
+	if w.s.state != stateNavigate {
+		return nil
+	}
+
+	x, y := w.game.GetCursor()
+	if train := w.game.GetTrainAt(y, x); train != nil {
+		w.game.RemoveTrain(train)
+		return nil
+	}
+
+	s := w.game.DeconstructStructure(y, x)
+	if s != nil && s == w.s.wireFrom {
+		w.s.wireFrom = nil
+	}
+
+	return nil
+}
+
+// click moves the cursor to the clicked tile, placing the ghost if there is one
+func (w *GameMapWidget) click(g *gocui.Gui, v *gocui.View) error {
+	vx, vy := v.Cursor()
+	x, y := w.game.GetCursor()
+
+	switch w.s.state {
+	case stateOverview:
+		tx := clamp((w.overviewFirst.x+vx)*OverviewCellTiles, 0, len(w.game.WorldMap[0])-1)
+		ty := clamp((w.overviewFirst.y+vy)*OverviewCellTiles, 0, len(w.game.WorldMap)-1)
+		w.game.MoveCursor(tx-x, ty-y)
+
+		return w.confirm(g, v)
+	case stateNavigate, stateStructureGhost, stateBlueprintSelect, stateBlueprintGhost, stateDeconstructSelect, stateRouteTarget:
+	default:
+		// the belt path only grows one tile at a time
+		return nil
+	}
+
+	if err := w.move(w.offsetX+vx-x, w.offsetY+vy-y)(g, v); err != nil {
+		return err
+	}
+
+	switch w.s.state {
+	case stateStructureGhost, stateBlueprintGhost:
+		return w.confirm(g, v)
+	}
+
+	return nil
+}
+
+// rightClick removes the Train or the Structure at the clicked tile
+func (w *GameMapWidget) rightClick(g *gocui.Gui, v *gocui.View) error {
+	if w.s.state != stateNavigate {
+		return nil
+	}
+
+	vx, vy := v.Cursor()
+	x, y := w.game.GetCursor()
+	if err := w.move(w.offsetX+vx-x, w.offsetY+vy-y)(g, v); err != nil {
+		return err
+	}
+
+	return w.remove(g, v)
+}
+
+// scroll moves the visible part of the map by rows, keeping the cursor on it
+func (w *GameMapWidget) scroll(dy int) func(g *gocui.Gui, v *gocui.View) error {
+	return func(g *gocui.Gui, v *gocui.View) error {
+		switch w.s.state {
+		case stateOverview:
+			return w.move(0, dy/MouseWheelRows)(g, v)
+		case stateBeltDrag:
+			return nil
+		}
+
+		_, height := v.Size()
+		w.offsetY = clamp(w.offsetY+dy, 0, len(w.game.WorldMap)-height)
+
+		_, y := w.game.GetCursor()
+		w.game.MoveCursor(0, clamp(y, w.offsetY, w.offsetY+height-1)-y)
+
+		return nil
+	}
+}
+
+// confirm applies the action of the current state at the cursor
+func (w *GameMapWidget) confirm(g *gocui.Gui, v *gocui.View) error {
+	if w.s.state == stateOverview {
+		// jump to the location chosen in the overview, scrolling the map to it
+		x, y := w.game.GetCursor()
+		w.game.MoveCursor(w.s.overviewFrom.x-x, w.s.overviewFrom.y-y)
+		w.s.state = stateNavigate
+
+		return w.move(x-w.s.overviewFrom.x, y-w.s.overviewFrom.y)(g, v)
+	}
+
+	if w.s.state == stateBlueprintSelect {
+		x, y := w.game.GetCursor()
+		name := fmt.Sprintf("Blueprint %d", len(w.s.library.Blueprints())+1)
+		blueprint := w.game.CaptureBlueprint(name, w.s.anchor, position{x: x, y: y})
+		if len(blueprint.Entries()) == 0 {
+			w.s.state = stateNavigate
+			return nil
+		}
+
+		w.s.err = w.s.library.Add(blueprint)
+		w.s.blueprint = blueprint.CopyBlueprint()
+		w.s.state = stateBlueprintGhost
+
+		return nil
+	}
+
+	if w.s.state == stateBlueprintGhost {
+		x, y := w.game.GetCursor()
+		w.game.PlaceBlueprint(y, x, w.s.blueprint)
+
+		return nil
+	}
+
+	if w.s.state == stateDeconstructSelect {
+		w.s.state = stateDeconstructConfirm
+
+		return nil
+	}
+
+	if w.s.state == stateRouteTarget {
+		x, y := w.game.GetCursor()
+		to, ok := w.game.FreeInput(y, x)
+		if !ok {
+			return nil
+		}
+
+		w.s.route = w.game.RouteBelts(w.s.routeFrom, to)
+		if w.s.route != nil {
+			w.s.state = stateRouteConfirm
+		}
+
+		return nil
+	}
+
+	if w.s.state == stateRouteConfirm {
+		if w.game.PlaceRoute(w.s.route) {
+			w.s.route = nil
+			w.s.state = stateNavigate
+		}
+
+		return nil
+	}
+
+	if w.s.state == stateBeltDrag {
+		w.game.PlaceBeltLine(w.s.path)
+		w.s.path = nil
+		w.s.state = stateNavigate
+
+		return nil
+	}
+
+	if w.s.ghost != nil {
+		copy := w.s.ghost.CopyStructure()
+		x, y := w.game.GetCursor()
+		if !w.game.BuildStructure(y, x, copy) {
+			return nil
+		}
+
+		product := GlobalProductFactory.GetProduct(copy.GetCode())
+
+		switch w.s.ghost.(type) {
+		case *Factory:
+			w.s.ghost = nil
+			w.s.state = stateSetRecipe
+		}
+
+		if w.s.state != stateSetRecipe {
+			count, isPresent := w.game.inventory.objects[product]
+			if !isPresent || count == 0 {
+				w.s.state = stateStructureSelect
+				w.s.ghost = nil
+				return nil
+			}
+		}
+	}
+	return nil
+}
+
+// bind binds the handler to the Command on the map, the pending count prefix and jump are dropped
+func (w *GameMapWidget) bind(g *gocui.Gui, c Command, handler func(*gocui.Gui, *gocui.View) error) error {
+	return GlobalKeymap.SetKeybinding(g, w.name, c,
+		func(g *gocui.Gui, v *gocui.View) error {
+			w.s.count = 0
+			w.s.jump = false
+
+			return handler(g, v)
+		})
+}
+
+// countDigit adds the digit to the count prefix of the next move, a leading 0 is ignored
+func (w *GameMapWidget) countDigit(digit int) func(g *gocui.Gui, v *gocui.View) error {
+	return func(g *gocui.Gui, v *gocui.View) error {
+		if w.s.count*10+digit <= CountMax {
+			w.s.count = w.s.count*10 + digit
+		}
+
+		return nil
+	}
+}
+
+// motion moves the cursor by the tiles returned by steps, as many times as the count prefix; after a jump
+// the cursor stops at the next edge of a Structure or of a resource patch instead
+func (w *GameMapWidget) motion(dx, dy int, steps func(v *gocui.View) int) func(g *gocui.Gui, v *gocui.View) error {
+	return func(g *gocui.Gui, v *gocui.View) error {
+		count := 1
+		if w.s.count > 0 {
+			count = w.s.count
+		}
+		jump := w.s.jump && w.s.state != stateOverview
+
+		w.s.count = 0
+		w.s.jump = false
+
+		for i := 0; i < count; i++ {
+			n := steps(v)
+			if jump {
+				x, y := w.game.GetCursor()
+				n = w.game.EdgeDistance(x, y, dx, dy)
+			}
+
+			// one tile at a time, the belt path only grows with adjacent tiles
+			for j := 0; j < n; j++ {
+				if err := w.move(dx, dy)(g, v); err != nil {
+					return err
+				}
+			}
+		}
+
+		return nil
+	}
+}
+
+func (w *GameMapWidget) move(dx, dy int) func(g *gocui.Gui, v *gocui.View) error {
+	return func(g *gocui.Gui, v *gocui.View) error {
+		cx, cy := w.game.GetCursor()
+
+		if w.s.state == stateOverview {
+			// pan one character of the zoomed-out map at a time, stopping at the map edges
+			tx := clamp(cx+dx*OverviewCellTiles, 0, len(w.game.WorldMap[0])-1)
+			ty := clamp(cy+dy*OverviewCellTiles, 0, len(w.game.WorldMap)-1)
+			w.game.MoveCursor(tx-cx, ty-cy)
+
+			return nil
+		}
+
+		cx += dx
+		cy += dy
+
+		if !w.game.WithinBounds(cx, cy) {
+			// do nothing if would be moved outside of bounds
+			return nil
+		}
+
+		maxX, maxY := v.Size()
+		if cx < w.offsetX {
+			w.offsetX = cx
+		} else if cx >= w.offsetX+maxX {
+			w.offsetX = cx - maxX + 1
+		}
+
+		if cy < w.offsetY {
+			w.offsetY = cy
+		} else if cy >= w.offsetY+maxY {
+			w.offsetY = cy - maxY + 1
+		}
+
+		w.game.MoveCursor(dx, dy)
+
+		if w.s.state == stateBeltDrag {
+			w.extendPath(position{x: cx, y: cy})
+		}
+
+		return nil
+	}
+}
+
+// extendPath adds the position to the belt path, moving back over the path shortens it
+func (w *GameMapWidget) extendPath(p position) {
+	for i, visited := range w.s.path {
+		if visited == p {
+			w.s.path = w.s.path[:i+1]
+			return
+		}
+	}
+
+	w.s.path = append(w.s.path, p)
+}
+
+// StructureSelectorWidget a GameWidget that displays available Structures to build
+type StructureSelectorWidget struct {
+	name    string
+	offsetY int
+	width   int
+	height  int
+
+	game *Game
+	s    *state
+
+	position int
+}
+
+func newStructureSelectorWidget() *StructureSelectorWidget {
+	w := new(StructureSelectorWidget)
+
+	return w
+}
+
+// SetGame sets the Game associated with StructureSelectorWidget
+func (w *StructureSelectorWidget) SetGame(game *Game) {
+	w.game = game
+}
+
+// Layout displays the StructureSelectorWidget
+func (w *StructureSelectorWidget) Layout(g *gocui.Gui) error {
+	if w.s.state != stateStructureSelect && w.s.state != stateStructureGhost {
+		return nil
+	}
+
+	maxX, _ := g.Size()
+
+	v, err := g.SetView(w.name, maxX-w.width, w.offsetY, maxX-1, w.offsetY+w.height)
+	if err != nil && err != gocui.ErrUnknownView {
+		return err
+	}
+
+	if err == gocui.ErrUnknownView {
+		err = w.initBindings(g)
+		if err == nil {
+			return err
+		}
+	}
+
+	if _, err := g.SetViewOnTop(w.name); err != nil {
+		return err
+	}
+
+	if w.s.state == stateStructureSelect {
+		if _, err := g.SetCurrentView(w.name); err != nil {
+			return err
+		}
+	}
+
+	v.Title = "Structures"
+
+	v.Clear()
+
+	start := w.first()
+
+	crtProduct, _, products := w.getProduct()
+	for index, product := range products {
+		if index < start {
+			continue
+		}
+
+		var prefix string
+		if crtProduct == product {
+			prefix = ">"
+		} else {
+			prefix = " "
+		}
+
+		count, _ := w.game.inventory.objects[product]
+		fmt.Fprintf(v, "%s %2d x %s\n", prefix, count, product.name)
+	}
+
+	return nil
+}
+
+func (w *StructureSelectorWidget) initBindings(g *gocui.Gui) error {
+	if err := g.SetKeybinding(w.name, gocui.KeyArrowDown, gocui.ModNone,
+		w.move(1)); err != nil {
+		return err
+	}
+	if err := g.SetKeybinding(w.name, gocui.KeyArrowUp, gocui.ModNone,
+		w.move(-1)); err != nil {
+		return err
+	}
+	if err := g.SetKeybinding(w.name, gocui.MouseLeft, gocui.ModNone,
+		func(g *gocui.Gui, v *gocui.View) error {
+			_, line := v.Cursor()
+			_, total, products := w.getProduct()
+
+			index := w.first() + line
+			if index >= total {
+				return nil
+			}
+
+			w.position = index
+			if w.s.state == stateStructureGhost {
+				w.s.ghost = products[index].structure
+			}
+
+			return nil
+		}); err != nil {
+		return err
+	}
+	if err := GlobalKeymap.SetKeybinding(g, w.name, CommandConfirm,
+		func(g *gocui.Gui, v *gocui.View) error {
+			product, _, _ := w.getProduct()
+			if product == nil {
+				w.s.state = stateNavigate
+				return nil
+			}
+
+			w.s.ghost = product.structure
+			w.s.state = stateStructureGhost
+
+			return nil
+		}); err != nil {
+		return err
+	}
+	if err := GlobalKeymap.SetKeybinding(g, w.name, CommandCancel,
+		func(g *gocui.Gui, v *gocui.View) error {
+			w.s.ghost = nil
+			w.s.state = stateNavigate
+
+			return nil
+		}); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (w *StructureSelectorWidget) move(d int) func(g *gocui.Gui, v *gocui.View) error {
+	return func(g *gocui.Gui, v *gocui.View) error {
+		prod, total, _ := w.getProduct()
+		if prod == nil {
+			w.position = total - 1
+		}
+
+		w.position += d + total
+		w.position %= total
+
+		return nil
+	}
+}
+
+// first returns the index of the first visible Product, the list scrolls to keep the selection visible
+func (w *StructureSelectorWidget) first() int {
+	if visible := w.height - 1; w.position >= visible {
+		return w.position - visible + 1
+	}
+
+	return 0
+}
+
+func (w *StructureSelectorWidget) getProduct() (*Product, int, []*Product) {
+	var returnProduct *Product
+	products := make([]*Product, 0)
+
+	index := -1
+	for _, product := range GlobalProductFactory.cannonicalOrder {
+		if product.structure == nil {
+			continue
+		}
+
+		_, isPresent := w.game.inventory.objects[product]
+		if !isPresent {
+			continue
+		}
+
+		index++
+
+		if index == w.position {
+			returnProduct = product
+		}
+		products = append(products, product)
+	}
+
+	return returnProduct, index + 1, products
+}
+
+// InventoryWidget a GameWidget that displays the full player inventory for transfer purposes
+type InventoryWidget struct {
+	name    string
+	offsetY int
+	width   int
+	height  int
+
+	game *Game
+	s    *state
+
+	storageIndex int
+	activeState  int
+
+	position int
+}
+
+func newInventoryWidget() *InventoryWidget {
+	w := new(InventoryWidget)
+
+	return w
+}
+
+// SetGame sets the Game associated with InventoryWidget
+func (w *InventoryWidget) SetGame(game *Game) {
+	w.game = game
+}
+
+// Layout displays the InventoryWidget
+func (w *InventoryWidget) Layout(g *gocui.Gui) error {
+	if w.s.state != stateMoveFromInventory && w.s.state != stateMoveFromStructure {
+		return nil
+	}
+
+	maxX, _ := g.Size()
+
+	v, err := g.SetView(w.name, maxX-w.width, w.offsetY, maxX-1, w.offsetY+w.height)
+	if err != nil && err != gocui.ErrUnknownView {
+		return err
+	}
+
+	if err == gocui.ErrUnknownView {
+		err = w.initBindings(g)
+		if err == nil {
+			return err
+		}
+	}
+
+	if _, err := g.SetViewOnTop(w.name); err != nil {
+		return err
+	}
+
+	if w.activeState == w.s.state {
+		if _, err := g.SetCurrentView(w.name); err != nil {
+			return err
+		}
+	}
+
+	v.Title = w.name
+
+	v.Clear()
+
+	storage := w.s.st[w.storageIndex]
+
+	if storage.Size() == 0 {
+		fmt.Fprint(v, "Empty inventory\n")
+		return nil
+	}
+
+	if w.position >= storage.Size() {
+		w.position = storage.Size() - 1
+	}
+
+	start := w.first()
+
+	index := -1
+	for _, product := range GlobalProductFactory.cannonicalOrder {
+		count, present := storage.objects[product]
+		if !present {
+			continue
+		}
+
+		index++
+		if index < start {
+			continue
+		}
+
+		var prefix string
+		if index == w.position {
+			if w.activeState == w.s.state {
+				prefix = ">"
+			} else {
+				prefix = " "
+			}
+		} else {
+			prefix = " "
+		}
+
+		fmt.Fprintf(v, "%s %3d x %s\n", prefix, count, product.name)
+	}
+
+	return nil
+}
+
+func (w *InventoryWidget) initBindings(g *gocui.Gui) error {
+	if err := g.SetKeybinding(w.name, gocui.KeyArrowDown, gocui.ModNone,
+		w.move(1)); err != nil {
+		return err
+	}
+	if err := g.SetKeybinding(w.name, gocui.KeyArrowUp, gocui.ModNone,
+		w.move(-1)); err != nil {
+		return err
+	}
+	if err := GlobalKeymap.SetKeybinding(g, w.name, CommandCancel,
+		func(g *gocui.Gui, v *gocui.View) error {
+			w.position = 0
+			w.s.state = stateNavigate
+
+			return nil
+		}); err != nil {
+		return err
+	}
+	if err := g.SetKeybinding(w.name, gocui.MouseLeft, gocui.ModNone,
+		func(g *gocui.Gui, v *gocui.View) error {
+			// clicking the other inventory moves the focus to it
+			w.s.state = w.activeState
+
+			_, line := v.Cursor()
+			index := w.first() + line
+			if index < w.s.st[w.storageIndex].UniqueObjects() {
+				w.position = index
+			}
+
+			return nil
+		}); err != nil {
+		return err
+	}
+	if err := GlobalKeymap.SetKeybinding(g, w.name, CommandTransfer,
+		func(g *gocui.Gui, v *gocui.View) error {
+			if w.s.state == stateMoveFromInventory {
+				w.s.state = stateMoveFromStructure
+			} else {
+				w.s.state = stateMoveFromInventory
+			}
+
+			return nil
+		}); err != nil {
+		return err
+	}
+	if err := GlobalKeymap.SetKeybinding(g, w.name, CommandDelete,
+		func(g *gocui.Gui, v *gocui.View) error {
+			product := w.getProduct()
+			w.game.TransferProducts(w.s.st[w.storageIndex], nil, product, 1)
+
+			return nil
+		}); err != nil {
+		return err
+	}
+	if err := GlobalKeymap.SetKeybinding(g, w.name, CommandConfirm,
+		func(g *gocui.Gui, v *gocui.View) error {
+			product := w.getProduct()
+
+			storage := w.s.st[w.storageIndex]
+			otherStorage := w.s.st[(w.storageIndex+1)%2]
+
+			w.game.TransferProducts(storage, otherStorage, product, 1)
+
+			return nil
+		}); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// first returns the index of the first visible Product, the last 5 Product-s are always visible
+func (w *InventoryWidget) first() int {
+	uniqueCount := w.s.st[w.storageIndex].UniqueObjects()
+	if uniqueCount-5 > w.position {
+		return w.position
+	}
+
+	if uniqueCount < 5 {
+		return 0
+	}
+
+	return uniqueCount - 5
+}
+
+func (w *InventoryWidget) getProduct() *Product {
+	storage := w.s.st[w.storageIndex]
+
+	index := -1
+	for _, product := range GlobalProductFactory.cannonicalOrder {
+		_, present := storage.objects[product]
+		if !present {
+			continue
+		}
+
+		index++
+		if index < w.position {
+			continue
+		}
+
+		if index > w.position {
+			break
+		}
+
+		return product
+	}
+
+	return nil
+}
+
+func (w *InventoryWidget) move(d int) func(g *gocui.Gui, v *gocui.View) error {
+	return func(g *gocui.Gui, v *gocui.View) error {
+		if w.activeState != w.s.state {
+			return nil
+		}
+
+		size := w.s.st[w.storageIndex].UniqueObjects()
+		if size == 0 {
+			return nil
+		}
+
+		newPosition := w.position + d
+		if newPosition >= 0 && newPosition < size {
+			w.position = newPosition
+		}
+
+		return nil
+	}
+}
+
+// RecipeSelectorWidget a GameWidget that displays available recipes
+type RecipeSelectorWidget struct {
+	name    string
+	offsetY int
+	width   int
+	height  int
+
+	game *Game
+	s    *state
+
+	position int
+}
+
+func newRecipeSelectorWidget() *RecipeSelectorWidget {
+	w := new(RecipeSelectorWidget)
+
+	return w
+}
+
+// SetGame sets the Game associated with RecipeSelectorWidget
+func (w *RecipeSelectorWidget) SetGame(game *Game) {
+	w.game = game
+}
+
+// Layout displays the RecipeSelectorWidget
+func (w *RecipeSelectorWidget) Layout(g *gocui.Gui) error {
+	if w.s.state != stateSetRecipe {
+		return nil
+	}
+
+	maxX, _ := g.Size()
+
+	v, err := g.SetView(w.name, maxX-w.width, w.offsetY, maxX-1, w.offsetY+w.height)
+	if err != nil && err != gocui.ErrUnknownView {
+		return err
+	}
+
+	if err == gocui.ErrUnknownView {
+		err = w.initBindings(g)
+		if err == nil {
+			return err
+		}
+	}
+
+	if _, err := g.SetViewOnTop(w.name); err != nil {
+		return err
+	}
+
+	if _, err := g.SetCurrentView(w.name); err != nil {
+		return err
+	}
+
+	v.Title = w.name
+	v.Clear()
+
+	maxPrintLines := w.height - 2
+	printedLines := 0
+
+	for index, recipe := range GlobalRecipeFactory.Assembly {
+		if index < w.position {
+			continue
+		}
+
+		if printedLines > maxPrintLines {
+			break
+		}
+
+		var prefix string
+		if index == w.position {
+			prefix = ">"
+		} else {
+			prefix = " "
+		}
+
+		fmt.Fprintf(v, "%s %s\n", prefix, recipe.output.name)
+		printedLines++
+
+		for _, product := range recipe.inputOrder {
+			fmt.Fprintf(v, "    %2d x %s\n", recipe.input[product], product.name)
+			printedLines++
+		}
+
+	}
+
+	return nil
+}
+
+func (w *RecipeSelectorWidget) initBindings(g *gocui.Gui) error {
+	if err := g.SetKeybinding(w.name, gocui.KeyArrowDown, gocui.ModNone,
+		w.move(1)); err != nil {
+		return err
+	}
+	if err := g.SetKeybinding(w.name, gocui.KeyArrowUp, gocui.ModNone,
+		w.move(-1)); err != nil {
+		return err
+	}
+	if err := GlobalKeymap.SetKeybinding(g, w.name, CommandCancel,
+		func(g *gocui.Gui, v *gocui.View) error {
+			w.position = 0
+			w.s.state = stateNavigate
+
+			return nil
+		}); err != nil {
+		return err
+	}
+	if err := g.SetKeybinding(w.name, gocui.MouseLeft, gocui.ModNone,
+		func(g *gocui.Gui, v *gocui.View) error {
+			_, line := v.Cursor()
+
+			// the list starts with the selected Recipe, each Recipe takes a line for each of its inputs
+			printed := 0
+			for index := w.position; index < len(GlobalRecipeFactory.Assembly); index++ {
+				printed += 1 + len(GlobalRecipeFactory.Assembly[index].inputOrder)
+				if line < printed {
+					w.position = index
+					break
+				}
+			}
+
+			return nil
+		}); err != nil {
+		return err
+	}
+	if err := GlobalKeymap.SetKeybinding(g, w.name, CommandConfirm,
+		func(g *gocui.Gui, v *gocui.View) error {
+			x, y := w.game.GetCursor()
+			s, _, _ := w.game.GetStructureAt(y, x)
+			if s == nil {
+				// this should not happen, this should only display if on a structure
+				return nil
+			}
+
+			switch f := s.(type) {
+			case *Factory:
+				// the change is refused when the inventory cannot take the Product-s of the Factory
+				if w.game.ChangeRecipe(f, GlobalRecipeFactory.Assembly[w.position]) {
+					w.s.state = stateNavigate
+				}
+			}
+
+			return nil
+		}); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (w *RecipeSelectorWidget) move(d int) func(g *gocui.Gui, v *gocui.View) error {
+	return func(g *gocui.Gui, v *gocui.View) error {
+		size := len(GlobalRecipeFactory.Assembly)
+
+		newPosition := w.position + d
+		if newPosition >= 0 && newPosition < size {
+			w.position = newPosition
+		}
+
+		return nil
+	}
+}
+
+// TrainScheduleWidget a GameWidget that edits the schedule of a Train
+type TrainScheduleWidget struct {
+	name    string
+	offsetY int
+	width   int
+	height  int
+
+	game *Game
+	s    *state
+
+	position int
+}
+
+func newTrainScheduleWidget() *TrainScheduleWidget {
+	w := new(TrainScheduleWidget)
+
+	return w
+}
+
+// SetGame sets the Game associated with TrainScheduleWidget
+func (w *TrainScheduleWidget) SetGame(game *Game) {
+	w.game = game
+}
+
+// Layout displays the TrainScheduleWidget
+func (w *TrainScheduleWidget) Layout(g *gocui.Gui) error {
+	if w.s.state != stateEditSchedule {
+		return nil
+	}
+
+	maxX, _ := g.Size()
+
+	v, err := g.SetView(w.name, maxX-w.width, w.offsetY, maxX-1, w.offsetY+w.height)
+	if err != nil && err != gocui.ErrUnknownView {
+		return err
+	}
+
+	if err == gocui.ErrUnknownView {
+		err = w.initBindings(g)
+		if err == nil {
+			return err
+		}
+	}
+
+	if _, err := g.SetViewOnTop(w.name); err != nil {
+		return err
+	}
+
+	if _, err := g.SetCurrentView(w.name); err != nil {
+		return err
+	}
+
+	v.Title = w.s.train.Name()
+	v.Clear()
+
+	schedule := w.s.train.Schedule()
+	if len(schedule) == 0 {
+		fmt.Fprint(v, "Empty schedule\n")
+		return nil
+	}
+
+	start := 0
+	if visible := w.height - 1; w.position >= visible {
+		start = w.position - visible + 1
+	}
+
+	for index, entry := range schedule {
+		if index < start {
+			continue
+		}
+
+		var prefix string
+		if index == w.position {
+			prefix = ">"
+		} else {
+			prefix = " "
+		}
+
+		action := "unload"
+		if entry.load {
+			action = "load"
+		}
+
+		fmt.Fprintf(v, "%s %s %s\n", prefix, entry.station.Name(), action)
+	}
+
+	return nil
+}
+
+func (w *TrainScheduleWidget) initBindings(g *gocui.Gui) error {
+	if err := g.SetKeybinding(w.name, gocui.KeyArrowDown, gocui.ModNone,
+		w.move(1)); err != nil {
+		return err
+	}
+	if err := g.SetKeybinding(w.name, gocui.KeyArrowUp, gocui.ModNone,
+		w.move(-1)); err != nil {
+		return err
+	}
+	if err := g.SetKeybinding(w.name, gocui.KeyArrowLeft, gocui.ModNone,
+		w.changeStation(-1)); err != nil {
+		return err
+	}
+	if err := g.SetKeybinding(w.name, gocui.KeyArrowRight, gocui.ModNone,
+		w.changeStation(1)); err != nil {
+		return err
+	}
+	if err := GlobalKeymap.SetKeybinding(g, w.name, CommandAdd,
+		func(g *gocui.Gui, v *gocui.View) error {
+			stations := w.game.Stations()
+			if len(stations) == 0 {
+				return nil
+			}
+
+			schedule := w.s.train.Schedule()
+			index := 0
+			if len(schedule) != 0 {
+				index = w.position + 1
+			}
+
+			entry := ScheduleEntry{station: stations[0], load: true}
+			newSchedule := make([]ScheduleEntry, 0, len(schedule)+1)
+			newSchedule = append(newSchedule, schedule[:index]...)
+			newSchedule = append(newSchedule, entry)
+			newSchedule = append(newSchedule, schedule[index:]...)
+
+			w.s.train.SetSchedule(newSchedule)
+			w.position = index
+
+			return nil
+		}); err != nil {
+		return err
+	}
+	if err := GlobalKeymap.SetKeybinding(g, w.name, CommandLoad,
+		func(g *gocui.Gui, v *gocui.View) error {
+			schedule := w.s.train.Schedule()
+			if len(schedule) == 0 {
+				return nil
+			}
+
+			schedule[w.position].load = !schedule[w.position].load
+
+			return nil
+		}); err != nil {
+		return err
+	}
+	if err := GlobalKeymap.SetKeybinding(g, w.name, CommandDelete,
+		func(g *gocui.Gui, v *gocui.View) error {
+			schedule := w.s.train.Schedule()
+			if len(schedule) == 0 {
+				return nil
+			}
+
+			newSchedule := make([]ScheduleEntry, 0, len(schedule)-1)
+			newSchedule = append(newSchedule, schedule[:w.position]...)
+			newSchedule = append(newSchedule, schedule[w.position+1:]...)
+			w.s.train.SetSchedule(newSchedule)
+
+			if w.position > 0 && w.position >= len(newSchedule) {
+				w.position--
+			}
+
+			return nil
+		}); err != nil {
+		return err
+	}
+	if err := GlobalKeymap.SetKeybinding(g, w.name, CommandCancel,
+		func(g *gocui.Gui, v *gocui.View) error {
+			w.position = 0
+			w.s.train = nil
+			w.s.state = stateNavigate
+
+			return nil
+		}); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (w *TrainScheduleWidget) move(d int) func(g *gocui.Gui, v *gocui.View) error {
 	return func(g *gocui.Gui, v *gocui.View) error {
-		cx, cy := w.game.GetCursor()
-		cx += dx
-		cy += dy
+		size := len(w.s.train.Schedule())
+
+		newPosition := w.position + d
+		if newPosition >= 0 && newPosition < size {
+			w.position = newPosition
+		}
+
+		return nil
+	}
+}
+
+func (w *TrainScheduleWidget) changeStation(d int) func(g *gocui.Gui, v *gocui.View) error {
+	return func(g *gocui.Gui, v *gocui.View) error {
+		schedule := w.s.train.Schedule()
+		stations := w.game.Stations()
+		if len(schedule) == 0 || len(stations) == 0 {
+			return nil
+		}
+
+		index := 0
+		for i, station := range stations {
+			if station == schedule[w.position].station {
+				index = i
+				break
+			}
+		}
+
+		index = (index + d + len(stations)) % len(stations)
+		schedule[w.position].station = stations[index]
+
+		return nil
+	}
+}
+
+// RequestWidget a GameWidget that edits the Product-s requested by a requester Chest
+type RequestWidget struct {
+	name    string
+	offsetY int
+	width   int
+	height  int
+
+	game *Game
+	s    *state
+
+	position int
+}
+
+func newRequestWidget() *RequestWidget {
+	w := new(RequestWidget)
+
+	return w
+}
+
+// SetGame sets the Game associated with RequestWidget
+func (w *RequestWidget) SetGame(game *Game) {
+	w.game = game
+}
+
+// Layout displays the RequestWidget
+func (w *RequestWidget) Layout(g *gocui.Gui) error {
+	if w.s.state != stateSetRequests {
+		return nil
+	}
+
+	maxX, _ := g.Size()
+
+	v, err := g.SetView(w.name, maxX-w.width, w.offsetY, maxX-1, w.offsetY+w.height)
+	if err != nil && err != gocui.ErrUnknownView {
+		return err
+	}
+
+	if err == gocui.ErrUnknownView {
+		err = w.initBindings(g)
+		if err == nil {
+			return err
+		}
+	}
+
+	if _, err := g.SetViewOnTop(w.name); err != nil {
+		return err
+	}
+
+	if _, err := g.SetCurrentView(w.name); err != nil {
+		return err
+	}
+
+	v.Title = w.name
+	v.Clear()
+
+	start := 0
+	if visible := w.height - 1; w.position >= visible {
+		start = w.position - visible + 1
+	}
+
+	requests := w.s.chest.Requests()
+	for index, product := range GlobalProductFactory.cannonicalOrder {
+		if index < start {
+			continue
+		}
+
+		var prefix string
+		if index == w.position {
+			prefix = ">"
+		} else {
+			prefix = " "
+		}
+
+		fmt.Fprintf(v, "%s %3d x %s\n", prefix, requests[product], product.name)
+	}
+
+	return nil
+}
+
+func (w *RequestWidget) initBindings(g *gocui.Gui) error {
+	if err := g.SetKeybinding(w.name, gocui.KeyArrowDown, gocui.ModNone,
+		w.move(1)); err != nil {
+		return err
+	}
+	if err := g.SetKeybinding(w.name, gocui.KeyArrowUp, gocui.ModNone,
+		w.move(-1)); err != nil {
+		return err
+	}
+	if err := g.SetKeybinding(w.name, gocui.KeyArrowLeft, gocui.ModNone,
+		w.change(-1)); err != nil {
+		return err
+	}
+	if err := g.SetKeybinding(w.name, gocui.KeyArrowRight, gocui.ModNone,
+		w.change(1)); err != nil {
+		return err
+	}
+	if err := GlobalKeymap.SetKeybinding(g, w.name, CommandRemove,
+		w.change(-ChestMaxStorage)); err != nil {
+		return err
+	}
+	if err := GlobalKeymap.SetKeybinding(g, w.name, CommandCancel,
+		func(g *gocui.Gui, v *gocui.View) error {
+			w.position = 0
+			w.s.chest = nil
+			w.s.state = stateNavigate
+
+			return nil
+		}); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (w *RequestWidget) move(d int) func(g *gocui.Gui, v *gocui.View) error {
+	return func(g *gocui.Gui, v *gocui.View) error {
+		size := len(GlobalProductFactory.cannonicalOrder)
+
+		newPosition := w.position + d
+		if newPosition >= 0 && newPosition < size {
+			w.position = newPosition
+		}
+
+		return nil
+	}
+}
+
+func (w *RequestWidget) change(d int) func(g *gocui.Gui, v *gocui.View) error {
+	return func(g *gocui.Gui, v *gocui.View) error {
+		product := GlobalProductFactory.cannonicalOrder[w.position]
+
+		count := w.s.chest.Requests()[product] + d
+		if count > ChestMaxStorage {
+			count = ChestMaxStorage
+		}
+
+		w.s.chest.SetRequest(product, count)
+
+		return nil
+	}
+}
+
+// circuitConfigurable indicates if the Structure can be controlled by, or publishes to, the circuit network
+func circuitConfigurable(s Structure) bool {
+	switch s.(type) {
+	case *Belt, *Extractor, *Factory, *ArithmeticCombinator, *DeciderCombinator:
+		return true
+	}
+
+	return false
+}
+
+// nextSignal cycles through the Product-s that can be used as signals
+func nextSignal(p *Product, d int) *Product {
+	signals := append([]*Product{nil}, GlobalProductFactory.cannonicalOrder...)
+
+	index := 0
+	for i, signal := range signals {
+		if signal == p {
+			index = i
+			break
+		}
+	}
+
+	index = (index + d + len(signals)) % len(signals)
+
+	return signals[index]
+}
+
+// CircuitWidget a GameWidget that edits a CircuitCondition or a combinator
+type CircuitWidget struct {
+	name    string
+	offsetY int
+	width   int
+	height  int
+
+	game *Game
+	s    *state
+
+	position int
+}
+
+func newCircuitWidget() *CircuitWidget {
+	w := new(CircuitWidget)
+
+	return w
+}
+
+// SetGame sets the Game associated with CircuitWidget
+func (w *CircuitWidget) SetGame(game *Game) {
+	w.game = game
+}
+
+// Layout displays the CircuitWidget
+func (w *CircuitWidget) Layout(g *gocui.Gui) error {
+	if w.s.state != stateEditCircuit {
+		return nil
+	}
+
+	maxX, _ := g.Size()
+
+	v, err := g.SetView(w.name, maxX-w.width, w.offsetY, maxX-1, w.offsetY+w.height)
+	if err != nil && err != gocui.ErrUnknownView {
+		return err
+	}
+
+	if err == gocui.ErrUnknownView {
+		err = w.initBindings(g)
+		if err == nil {
+			return err
+		}
+	}
+
+	if _, err := g.SetViewOnTop(w.name); err != nil {
+		return err
+	}
+
+	if _, err := g.SetCurrentView(w.name); err != nil {
+		return err
+	}
+
+	v.Title = w.name
+	v.Clear()
+
+	for index, field := range w.fields() {
+		var prefix string
+		if index == w.position {
+			prefix = ">"
+		} else {
+			prefix = " "
+		}
+
+		fmt.Fprintf(v, "%s %s\n", prefix, field)
+	}
+
+	return nil
+}
+
+// fields returns the editable values of the current circuit Structure
+func (w *CircuitWidget) fields() []string {
+	switch c := w.s.circuit.(type) {
+	case *ArithmeticCombinator:
+		return []string{
+			fmt.Sprintf("in : %s", signalName(c.signal)),
+			fmt.Sprintf("op : %s", operationSymbols[c.operation]),
+			fmt.Sprintf("val: %d", c.constant),
+			fmt.Sprintf("out: %s", signalName(c.result)),
+		}
+	case *DeciderCombinator:
+		return []string{
+			fmt.Sprintf("in : %s", signalName(c.condition.signal)),
+			fmt.Sprintf("op : %s", comparatorSymbols[c.condition.comparator]),
+			fmt.Sprintf("val: %d", c.condition.constant),
+			fmt.Sprintf("out: %s", signalName(c.result)),
+		}
+	}
+
+	condition := w.game.CircuitCondition(w.s.circuit)
+	if condition == nil {
+		return []string{}
+	}
+
+	return []string{
+		fmt.Sprintf("in : %s", signalName(condition.signal)),
+		fmt.Sprintf("op : %s", comparatorSymbols[condition.comparator]),
+		fmt.Sprintf("val: %d", condition.constant),
+	}
+}
+
+func (w *CircuitWidget) initBindings(g *gocui.Gui) error {
+	if err := g.SetKeybinding(w.name, gocui.KeyArrowDown, gocui.ModNone,
+		w.move(1)); err != nil {
+		return err
+	}
+	if err := g.SetKeybinding(w.name, gocui.KeyArrowUp, gocui.ModNone,
+		w.move(-1)); err != nil {
+		return err
+	}
+	if err := g.SetKeybinding(w.name, gocui.KeyArrowLeft, gocui.ModNone,
+		w.change(-1)); err != nil {
+		return err
+	}
+	if err := g.SetKeybinding(w.name, gocui.KeyArrowRight, gocui.ModNone,
+		w.change(1)); err != nil {
+		return err
+	}
+	if err := GlobalKeymap.SetKeybinding(g, w.name, CommandDecrease,
+		w.change(-10)); err != nil {
+		return err
+	}
+	if err := GlobalKeymap.SetKeybinding(g, w.name, CommandIncrease,
+		w.change(10)); err != nil {
+		return err
+	}
+	if err := GlobalKeymap.SetKeybinding(g, w.name, CommandRemove,
+		func(g *gocui.Gui, v *gocui.View) error {
+			if _, ok := w.s.circuit.(Combinator); !ok {
+				w.game.SetCircuitCondition(w.s.circuit, nil)
+			}
+
+			w.position = 0
+			w.s.circuit = nil
+			w.s.state = stateNavigate
+
+			return nil
+		}); err != nil {
+		return err
+	}
+	if err := GlobalKeymap.SetKeybinding(g, w.name, CommandCancel,
+		func(g *gocui.Gui, v *gocui.View) error {
+			w.position = 0
+			w.s.circuit = nil
+			w.s.state = stateNavigate
 
-		if !w.game.WithinBounds(cx, cy) {
-			// do nothing if would be moved outside of bounds
 			return nil
+		}); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (w *CircuitWidget) move(d int) func(g *gocui.Gui, v *gocui.View) error {
+	return func(g *gocui.Gui, v *gocui.View) error {
+		size := len(w.fields())
+
+		newPosition := w.position + d
+		if newPosition >= 0 && newPosition < size {
+			w.position = newPosition
 		}
 
-		maxX, maxY := v.Size()
-		if cx < w.offsetX {
-			w.offsetX = cx
-		} else if cx >= w.offsetX+maxX {
-			w.offsetX = cx - maxX + 1
+		return nil
+	}
+}
+
+func (w *CircuitWidget) change(d int) func(g *gocui.Gui, v *gocui.View) error {
+	return func(g *gocui.Gui, v *gocui.View) error {
+		step := 1
+		if d < 0 {
+			step = -1
 		}
 
-		if cy < w.offsetY {
-			w.offsetY = cy
-		} else if cy >= w.offsetY+maxY {
-			w.offsetY = cy - maxY + 1
+		var signal, result **Product
+		var operator *int
+		var constant *int
+		operators := len(comparatorSymbols)
+
+		switch c := w.s.circuit.(type) {
+		case *ArithmeticCombinator:
+			signal, operator, constant, result = &c.signal, &c.operation, &c.constant, &c.result
+			operators = len(operationSymbols)
+		case *DeciderCombinator:
+			signal, operator, constant, result = &c.condition.signal, &c.condition.comparator, &c.condition.constant, &c.result
+		default:
+			condition := w.game.CircuitCondition(w.s.circuit)
+			if condition == nil {
+				return nil
+			}
+			signal, operator, constant = &condition.signal, &condition.comparator, &condition.constant
 		}
 
-		w.game.MoveCursor(dx, dy)
+		switch w.position {
+		case 0:
+			*signal = nextSignal(*signal, step)
+		case 1:
+			*operator = (*operator + step + operators) % operators
+		case 2:
+			*constant += d
+		case 3:
+			if result != nil {
+				*result = nextSignal(*result, step)
+			}
+		}
 
 		return nil
 	}
 }
 
-// StructureSelectorWidget a GameWidget that displays available Structures to build
-type StructureSelectorWidget struct {
+// SignalsWidget a GameWidget that displays the signals on the CircuitNetwork under the cursor
+type SignalsWidget struct {
+	name    string
+	offsetY int
+	width   int
+	height  int
+
+	game *Game
+	s    *state
+}
+
+func newSignalsWidget() *SignalsWidget {
+	w := new(SignalsWidget)
+
+	return w
+}
+
+// SetGame sets the Game associated with SignalsWidget
+func (w *SignalsWidget) SetGame(game *Game) {
+	w.game = game
+}
+
+// Layout displays the SignalsWidget
+func (w *SignalsWidget) Layout(g *gocui.Gui) error {
+	var network *CircuitNetwork
+	if w.s.state == stateNavigate {
+		x, y := w.game.GetCursor()
+		structure, _, _ := w.game.GetStructureAt(y, x)
+		if structure != nil {
+			network = w.game.GetNetwork(structure)
+		}
+	}
+
+	if network == nil {
+		return nil
+	}
+
+	maxX, _ := g.Size()
+
+	v, err := g.SetView(w.name, maxX-w.width, w.offsetY, maxX-1, w.offsetY+w.height)
+	if err != nil && err != gocui.ErrUnknownView {
+		return err
+	}
+
+	if _, err := g.SetViewOnTop(w.name); err != nil {
+		return err
+	}
+
+	v.Title = w.name
+	v.Clear()
+
+	signals := network.Signals()
+	if len(signals) == 0 {
+		fmt.Fprint(v, "No signals\n")
+		return nil
+	}
+
+	for _, product := range GlobalProductFactory.cannonicalOrder {
+		count, present := signals[product]
+		if !present || count == 0 {
+			continue
+		}
+
+		fmt.Fprintf(v, "%5d x %s\n", count, product.name)
+	}
+
+	return nil
+}
+
+// BlueprintLibraryWidget a GameWidget that lists the saved Blueprint-s
+type BlueprintLibraryWidget struct {
 	name    string
 	offsetY int
 	width   int
@@ -605,20 +3078,20 @@ type StructureSelectorWidget struct {
 	position int
 }
 
-func newStructureSelectorWidget() *StructureSelectorWidget {
-	w := new(StructureSelectorWidget)
+func newBlueprintLibraryWidget() *BlueprintLibraryWidget {
+	w := new(BlueprintLibraryWidget)
 
 	return w
 }
 
-// SetGame sets the Game associated with StructureSelectorWidget
-func (w *StructureSelectorWidget) SetGame(game *Game) {
+// SetGame sets the Game associated with BlueprintLibraryWidget
+func (w *BlueprintLibraryWidget) SetGame(game *Game) {
 	w.game = game
 }
 
-// Layout displays the StructureSelectorWidget
-func (w *StructureSelectorWidget) Layout(g *gocui.Gui) error {
-	if w.s.state != stateStructureSelect && w.s.state != stateStructureGhost {
+// Layout displays the BlueprintLibraryWidget
+func (w *BlueprintLibraryWidget) Layout(g *gocui.Gui) error {
+	if w.s.state != stateBlueprintLibrary {
 		return nil
 	}
 
@@ -640,33 +3113,40 @@ func (w *StructureSelectorWidget) Layout(g *gocui.Gui) error {
 		return err
 	}
 
-	if w.s.state == stateStructureSelect {
-		if _, err := g.SetCurrentView(w.name); err != nil {
-			return err
-		}
+	if _, err := g.SetCurrentView(w.name); err != nil {
+		return err
 	}
 
-	v.Title = "Structures"
-
+	v.Title = w.name
 	v.Clear()
 
-	crtProduct, _, products := w.getProduct()
-	for _, product := range products {
+	blueprints := w.s.library.Blueprints()
+	if len(blueprints) == 0 {
+		fmt.Fprint(v, "No blueprints\n")
+		return nil
+	}
+
+	start := 0
+	if w.position >= w.height-1 {
+		start = w.position - w.height + 2
+	}
+
+	for index := start; index < len(blueprints) && index < start+w.height-1; index++ {
 		var prefix string
-		if crtProduct == product {
+		if index == w.position {
 			prefix = ">"
 		} else {
 			prefix = " "
 		}
 
-		count, _ := w.game.inventory.objects[product]
-		fmt.Fprintf(v, "%s %2d x %s\n", prefix, count, product.name)
+		b := blueprints[index]
+		fmt.Fprintf(v, "%s %s %dx%d\n", prefix, b.Name(), b.width, b.height)
 	}
 
 	return nil
 }
 
-func (w *StructureSelectorWidget) initBindings(g *gocui.Gui) error {
+func (w *BlueprintLibraryWidget) initBindings(g *gocui.Gui) error {
 	if err := g.SetKeybinding(w.name, gocui.KeyArrowDown, gocui.ModNone,
 		w.move(1)); err != nil {
 		return err
@@ -675,118 +3155,138 @@ func (w *StructureSelectorWidget) initBindings(g *gocui.Gui) error {
 		w.move(-1)); err != nil {
 		return err
 	}
-	if err := g.SetKeybinding(w.name, gocui.KeySpace, gocui.ModNone,
+	if err := GlobalKeymap.SetKeybinding(g, w.name, CommandCancel,
 		func(g *gocui.Gui, v *gocui.View) error {
-			product, _, _ := w.getProduct()
-			if product == nil {
-				w.s.state = stateNavigate
+			w.s.state = stateNavigate
+
+			return nil
+		}); err != nil {
+		return err
+	}
+	if err := GlobalKeymap.SetKeybinding(g, w.name, CommandExport,
+		func(g *gocui.Gui, v *gocui.View) error {
+			blueprints := w.s.library.Blueprints()
+			if w.position >= len(blueprints) {
 				return nil
 			}
 
-			w.s.ghost = product.structure
-			w.s.state = stateStructureGhost
+			w.s.share = blueprints[w.position].String()
+			w.s.state = stateBlueprintExport
 
 			return nil
 		}); err != nil {
 		return err
 	}
-	if err := g.SetKeybinding(w.name, 'c', gocui.ModNone,
+	if err := GlobalKeymap.SetKeybinding(g, w.name, CommandImport,
 		func(g *gocui.Gui, v *gocui.View) error {
-			w.s.ghost = nil
-			w.s.state = stateNavigate
+			w.s.err = nil
+			w.s.state = stateBlueprintImport
 
 			return nil
 		}); err != nil {
 		return err
 	}
+	if err := GlobalKeymap.SetKeybinding(g, w.name, CommandRemove,
+		func(g *gocui.Gui, v *gocui.View) error {
+			w.s.err = w.s.library.Remove(w.position)
+			if w.position >= len(w.s.library.Blueprints()) && w.position > 0 {
+				w.position--
+			}
 
-	return nil
-}
-
-func (w *StructureSelectorWidget) move(d int) func(g *gocui.Gui, v *gocui.View) error {
-	return func(g *gocui.Gui, v *gocui.View) error {
-		prod, total, _ := w.getProduct()
-		if prod == nil {
-			w.position = total - 1
-		}
+			return nil
+		}); err != nil {
+		return err
+	}
+	if err := GlobalKeymap.SetKeybinding(g, w.name, CommandConfirm,
+		func(g *gocui.Gui, v *gocui.View) error {
+			blueprints := w.s.library.Blueprints()
+			if w.position >= len(blueprints) {
+				return nil
+			}
 
-		w.position += d + total
-		w.position %= total
+			w.s.blueprint = blueprints[w.position].CopyBlueprint()
+			w.s.state = stateBlueprintGhost
 
-		return nil
+			return nil
+		}); err != nil {
+		return err
 	}
-}
 
-func (w *StructureSelectorWidget) getProduct() (*Product, int, []*Product) {
-	var returnProduct *Product
-	products := make([]*Product, 0)
+	return nil
+}
 
-	index := -1
-	for _, product := range GlobalProductFactory.cannonicalOrder {
-		if product.structure == nil {
-			continue
-		}
+func (w *BlueprintLibraryWidget) move(d int) func(g *gocui.Gui, v *gocui.View) error {
+	return func(g *gocui.Gui, v *gocui.View) error {
+		size := len(w.s.library.Blueprints())
 
-		_, isPresent := w.game.inventory.objects[product]
-		if !isPresent {
-			continue
+		newPosition := w.position + d
+		if newPosition >= 0 && newPosition < size {
+			w.position = newPosition
 		}
 
-		index++
-
-		if index == w.position {
-			returnProduct = product
-		}
-		products = append(products, product)
+		return nil
 	}
-
-	return returnProduct, index + 1, products
 }
 
-// InventoryWidget a GameWidget that displays the full player inventory for transfer purposes
-type InventoryWidget struct {
-	name    string
-	offsetY int
-	width   int
-	height  int
+// BlueprintShareWidget a GameWidget that shows the text of a Blueprint to be copied, or reads a pasted one
+type BlueprintShareWidget struct {
+	name      string
+	reservedX int
+	height    int
+	importing bool
 
 	game *Game
 	s    *state
 
-	storageIndex int
-	activeState  int
-
-	position int
+	bound bool
 }
 
-func newInventoryWidget() *InventoryWidget {
-	w := new(InventoryWidget)
+func newBlueprintShareWidget(importing bool) *BlueprintShareWidget {
+	w := new(BlueprintShareWidget)
+	w.importing = importing
 
 	return w
 }
 
-// SetGame sets the Game associated with InventoryWidget
-func (w *InventoryWidget) SetGame(game *Game) {
+// SetGame sets the Game associated with BlueprintShareWidget
+func (w *BlueprintShareWidget) SetGame(game *Game) {
 	w.game = game
 }
 
-// Layout displays the InventoryWidget
-func (w *InventoryWidget) Layout(g *gocui.Gui) error {
-	if w.s.state != stateMoveFromInventory && w.s.state != stateMoveFromStructure {
+func (w *BlueprintShareWidget) active() bool {
+	if w.importing {
+		return w.s.state == stateBlueprintImport
+	}
+
+	return w.s.state == stateBlueprintExport
+}
+
+// Layout displays the BlueprintShareWidget
+func (w *BlueprintShareWidget) Layout(g *gocui.Gui) error {
+	if !w.active() {
 		return nil
 	}
 
-	maxX, _ := g.Size()
+	maxX, maxY := g.Size()
 
-	v, err := g.SetView(w.name, maxX-w.width, w.offsetY, maxX-1, w.offsetY+w.height)
+	v, err := g.SetView(w.name, 0, maxY-1-w.height, maxX-1-w.reservedX, maxY-1)
 	if err != nil && err != gocui.ErrUnknownView {
 		return err
 	}
 
 	if err == gocui.ErrUnknownView {
-		err = w.initBindings(g)
-		if err == nil {
-			return err
+		// the view is deleted when closed, so that it starts empty the next time
+		v.Wrap = true
+		v.Editable = w.importing
+		if !w.importing {
+			fmt.Fprint(v, w.s.share)
+		}
+
+		if !w.bound {
+			if err := w.initBindings(g); err != nil {
+				return err
+			}
+			w.bound = true
 		}
 	}
 
@@ -794,213 +3294,271 @@ func (w *InventoryWidget) Layout(g *gocui.Gui) error {
 		return err
 	}
 
-	if w.activeState == w.s.state {
-		if _, err := g.SetCurrentView(w.name); err != nil {
-			return err
-		}
+	if _, err := g.SetCurrentView(w.name); err != nil {
+		return err
 	}
 
+	g.Cursor = w.importing
 	v.Title = w.name
 
-	v.Clear()
+	return nil
+}
 
-	storage := w.s.st[w.storageIndex]
+func (w *BlueprintShareWidget) close(g *gocui.Gui) error {
+	w.s.state = stateBlueprintLibrary
 
-	if storage.Size() == 0 {
-		fmt.Fprint(v, "Empty inventory\n")
+	return g.DeleteView(w.name)
+}
+
+func (w *BlueprintShareWidget) initBindings(g *gocui.Gui) error {
+	if !w.importing {
+		return GlobalKeymap.SetKeybinding(g, w.name, CommandCancel,
+			func(g *gocui.Gui, v *gocui.View) error {
+				return w.close(g)
+			})
+	}
+
+	// the pasted text is typed in the view, so only the enter key is bound
+	return g.SetKeybinding(w.name, gocui.KeyEnter, gocui.ModNone,
+		func(g *gocui.Gui, v *gocui.View) error {
+			text := strings.Join(strings.Fields(v.Buffer()), "")
+			if text == "" {
+				return w.close(g)
+			}
+
+			name := fmt.Sprintf("Blueprint %d", len(w.s.library.Blueprints())+1)
+			blueprint, err := ParseBlueprint(name, text)
+			if err != nil {
+				w.s.err = err
+				return nil
+			}
+
+			w.s.err = w.s.library.Add(blueprint)
+
+			return w.close(g)
+		})
+}
+
+// DeconstructWidget a GameWidget that asks for confirmation before clearing an area
+type DeconstructWidget struct {
+	name    string
+	offsetY int
+	width   int
+	height  int
+
+	game *Game
+	s    *state
+}
+
+func newDeconstructWidget() *DeconstructWidget {
+	w := new(DeconstructWidget)
+
+	return w
+}
+
+// SetGame sets the Game associated with DeconstructWidget
+func (w *DeconstructWidget) SetGame(game *Game) {
+	w.game = game
+}
+
+// Layout displays the DeconstructWidget
+func (w *DeconstructWidget) Layout(g *gocui.Gui) error {
+	if w.s.state != stateDeconstructConfirm {
 		return nil
 	}
 
-	if w.position >= storage.Size() {
-		w.position = storage.Size() - 1
+	maxX, _ := g.Size()
+
+	v, err := g.SetView(w.name, maxX-w.width, w.offsetY, maxX-1, w.offsetY+w.height)
+	if err != nil && err != gocui.ErrUnknownView {
+		return err
+	}
+
+	if err == gocui.ErrUnknownView {
+		err = w.initBindings(g)
+		if err == nil {
+			return err
+		}
 	}
 
-	var start int
-	uniqueCount := storage.UniqueObjects()
-	if uniqueCount-5 > w.position {
-		start = w.position
-	} else {
-		start = uniqueCount - 5
+	if _, err := g.SetViewOnTop(w.name); err != nil {
+		return err
 	}
 
-	index := -1
-	for _, product := range GlobalProductFactory.cannonicalOrder {
-		count, present := storage.objects[product]
-		if !present {
-			continue
-		}
+	if _, err := g.SetCurrentView(w.name); err != nil {
+		return err
+	}
 
-		index++
-		if index < start {
-			continue
-		}
+	v.Title = w.name
+	v.Clear()
 
-		var prefix string
-		if index == w.position {
-			if w.activeState == w.s.state {
-				prefix = ">"
-			} else {
-				prefix = " "
-			}
-		} else {
-			prefix = " "
-		}
+	x, y := w.game.GetCursor()
+	structures, products := w.game.DeconstructionSummary(w.s.anchor, position{x: x, y: y})
+	free := w.game.inventory.Capacity() - w.game.inventory.Size()
 
-		fmt.Fprintf(v, "%s %3d x %s\n", prefix, count, product.name)
+	fmt.Fprintf(v, "Structures: %d\n", structures)
+	fmt.Fprintf(v, "Products  : %d\n", products)
+	fmt.Fprintf(v, "Free space: %d\n", free)
+	if structures+products > free {
+		fmt.Fprint(v, "\033[31;1mInventory full\033[0m\n")
 	}
+	fmt.Fprintf(v, "Overflow  : %s\n", overflowNames[w.s.overflow])
+	fmt.Fprint(v, "Remove? y/n\n")
 
 	return nil
 }
 
-func (w *InventoryWidget) initBindings(g *gocui.Gui) error {
-	if err := g.SetKeybinding(w.name, gocui.KeyArrowDown, gocui.ModNone,
-		w.move(1)); err != nil {
-		return err
-	}
-	if err := g.SetKeybinding(w.name, gocui.KeyArrowUp, gocui.ModNone,
-		w.move(-1)); err != nil {
-		return err
-	}
-	if err := g.SetKeybinding(w.name, 'c', gocui.ModNone,
+func (w *DeconstructWidget) initBindings(g *gocui.Gui) error {
+	if err := GlobalKeymap.SetKeybinding(g, w.name, CommandAccept,
 		func(g *gocui.Gui, v *gocui.View) error {
-			w.position = 0
+			x, y := w.game.GetCursor()
+			w.game.DeconstructArea(w.s.anchor, position{x: x, y: y}, w.s.overflow)
+			w.s.wireFrom = nil
 			w.s.state = stateNavigate
 
 			return nil
 		}); err != nil {
 		return err
 	}
-	if err := g.SetKeybinding(w.name, 't', gocui.ModNone,
+	if err := GlobalKeymap.SetKeybinding(g, w.name, CommandPolicy,
 		func(g *gocui.Gui, v *gocui.View) error {
-			if w.s.state == stateMoveFromInventory {
-				w.s.state = stateMoveFromStructure
-			} else {
-				w.s.state = stateMoveFromInventory
-			}
+			w.s.overflow = (w.s.overflow + 1) % overflowCount
 
 			return nil
 		}); err != nil {
 		return err
 	}
-	if err := g.SetKeybinding(w.name, 'd', gocui.ModNone,
-		func(g *gocui.Gui, v *gocui.View) error {
-			product := w.getProduct()
-			w.s.st[w.storageIndex].Remove(product, 1)
+	for _, a := range []Command{CommandDecline, CommandCancel} {
+		if err := GlobalKeymap.SetKeybinding(g, w.name, a,
+			func(g *gocui.Gui, v *gocui.View) error {
+				w.s.state = stateNavigate
 
-			return nil
-		}); err != nil {
-		return err
+				return nil
+			}); err != nil {
+			return err
+		}
 	}
-	if err := g.SetKeybinding(w.name, gocui.KeySpace, gocui.ModNone,
-		func(g *gocui.Gui, v *gocui.View) error {
-			product := w.getProduct()
 
-			storage := w.s.st[w.storageIndex]
-			otherStorage := w.s.st[(w.storageIndex+1)%2]
+	return nil
+}
 
-			added := otherStorage.Add(product, 1)
-			if added == 1 {
-				removed := storage.Remove(product, 1)
-				if removed == 1 {
-					return nil
-				}
+// AlertsWidget a GameWidget that lists the active alerts, it can be collapsed to a single line
+type AlertsWidget struct {
+	name    string
+	offsetY int
+	width   int
+	height  int
 
-				otherStorage.Remove(product, 1)
-			}
+	game *Game
+	s    *state
+}
 
-			return nil
-		}); err != nil {
-		return err
-	}
+func newAlertsWidget() *AlertsWidget {
+	w := new(AlertsWidget)
 
-	return nil
+	return w
 }
 
-func (w *InventoryWidget) getProduct() *Product {
-	storage := w.s.st[w.storageIndex]
+// SetGame sets the Game associated with AlertsWidget
+func (w *AlertsWidget) SetGame(game *Game) {
+	w.s.alert = 0
+	w.game = game
+}
 
-	index := -1
-	for _, product := range GlobalProductFactory.cannonicalOrder {
-		_, present := storage.objects[product]
-		if !present {
-			continue
-		}
+// Layout displays the AlertsWidget
+func (w *AlertsWidget) Layout(g *gocui.Gui) error {
+	if w.s.state != stateNavigate {
+		return nil
+	}
 
-		index++
-		if index < w.position {
-			continue
-		}
+	maxX, maxY := g.Size()
 
-		if index > w.position {
-			break
-		}
+	height := w.height
+	if w.s.alertsCollapsed {
+		height = 2
+	}
 
-		return product
+	// keep clear of the InfoWidget on small terminals
+	offsetY := maxY - 2 - height
+	if offsetY < w.offsetY {
+		offsetY = w.offsetY
 	}
 
-	return nil
-}
+	v, err := g.SetView(w.name, maxX-w.width, offsetY, maxX-1, maxY-2)
+	if err != nil && err != gocui.ErrUnknownView {
+		return err
+	}
 
-func (w *InventoryWidget) move(d int) func(g *gocui.Gui, v *gocui.View) error {
-	return func(g *gocui.Gui, v *gocui.View) error {
-		if w.activeState != w.s.state {
-			return nil
-		}
+	if _, err := g.SetViewOnTop(w.name); err != nil {
+		return err
+	}
 
-		size := w.s.st[w.storageIndex].UniqueObjects()
-		if size == 0 {
-			return nil
-		}
+	alerts := w.game.Alerts()
 
-		newPosition := w.position + d
-		if newPosition >= 0 && newPosition < size {
-			w.position = newPosition
-		}
+	v.Title = fmt.Sprintf("%s (%d)", w.name, len(alerts))
+	v.Clear()
 
+	if w.s.alertsCollapsed {
+		printHelp(v, "expand", CommandAlerts)
 		return nil
 	}
+
+	if len(alerts) == 0 {
+		fmt.Fprint(v, "none\n")
+	}
+
+	// the newest alerts first
+	for i := len(alerts) - 1; i >= 0 && len(alerts)-i < height; i-- {
+		prefix := " "
+		if i == w.s.alert {
+			prefix = ">"
+		}
+
+		fmt.Fprintf(v, "%s%s\n", prefix, alerts[i])
+	}
+
+	return nil
 }
 
-// RecipeSelectorWidget a GameWidget that displays available recipes
-type RecipeSelectorWidget struct {
-	name    string
-	offsetY int
-	width   int
-	height  int
+// MinimapWidget a GameWidget that displays the whole map downsampled with braille characters
+type MinimapWidget struct {
+	name  string
+	width int
 
 	game *Game
 	s    *state
-
-	position int
 }
 
-func newRecipeSelectorWidget() *RecipeSelectorWidget {
-	w := new(RecipeSelectorWidget)
+func newMinimapWidget() *MinimapWidget {
+	w := new(MinimapWidget)
 
 	return w
 }
 
-// SetGame sets the Game associated with RecipeSelectorWidget
-func (w *RecipeSelectorWidget) SetGame(game *Game) {
+// SetGame sets the Game associated with MinimapWidget
+func (w *MinimapWidget) SetGame(game *Game) {
 	w.game = game
 }
 
-// Layout displays the RecipeSelectorWidget
-func (w *RecipeSelectorWidget) Layout(g *gocui.Gui) error {
-	if w.s.state != stateSetRecipe {
+// Layout displays the MinimapWidget in the bottom left corner, over the map
+func (w *MinimapWidget) Layout(g *gocui.Gui) error {
+	if !w.s.minimap || w.s.state == stateOverview {
 		return nil
 	}
 
-	maxX, _ := g.Size()
+	_, maxY := g.Size()
 
-	v, err := g.SetView(w.name, maxX-w.width, w.offsetY, maxX-1, w.offsetY+w.height)
+	scale := w.game.MinimapScale(w.width - 2)
+	rows := (len(w.game.WorldMap) + 4*scale - 1) / (4 * scale)
+	columns := (len(w.game.WorldMap[0]) + 2*scale - 1) / (2 * scale)
+
+	v, err := g.SetView(w.name, 0, maxY-rows-3, columns+1, maxY-2)
 	if err != nil && err != gocui.ErrUnknownView {
 		return err
 	}
 
 	if err == gocui.ErrUnknownView {
-		err = w.initBindings(g)
-		if err == nil {
+		if err := w.initBindings(g); err != nil {
 			return err
 		}
 	}
@@ -1009,77 +3567,41 @@ func (w *RecipeSelectorWidget) Layout(g *gocui.Gui) error {
 		return err
 	}
 
-	if _, err := g.SetCurrentView(w.name); err != nil {
-		return err
-	}
-
 	v.Title = w.name
 	v.Clear()
 
-	maxPrintLines := w.height - 2
-	printedLines := 0
-
-	for index, recipe := range GlobalRecipeFactory.Assembly {
-		if index < w.position {
-			continue
-		}
-
-		if printedLines > maxPrintLines {
-			break
-		}
-
-		var prefix string
-		if index == w.position {
-			prefix = ">"
-		} else {
-			prefix = " "
-		}
-
-		fmt.Fprintf(v, "%s %s\n", prefix, recipe.output.name)
-		printedLines++
+	for i := 0; i < rows; i++ {
+		for j := 0; j < columns; j++ {
+			colorMode := 1
+			if i >= w.s.viewFrom.y/(4*scale) && i <= w.s.viewTo.y/(4*scale) &&
+				j >= w.s.viewFrom.x/(2*scale) && j <= w.s.viewTo.x/(2*scale) {
+				colorMode = 7
+			}
 
-		for _, product := range recipe.inputOrder {
-			fmt.Fprintf(v, "    %2d x %s\n", recipe.input[product], product.name)
-			printedLines++
+			glyph, kind := w.game.minimapCell(i, j, scale)
+			fmt.Fprint(v, displayMapCell(glyph, kind, colorMode))
 		}
-
+		fmt.Fprintln(v, "")
 	}
 
 	return nil
 }
 
-func (w *RecipeSelectorWidget) initBindings(g *gocui.Gui) error {
-	if err := g.SetKeybinding(w.name, gocui.KeyArrowDown, gocui.ModNone,
-		w.move(1)); err != nil {
-		return err
-	}
-	if err := g.SetKeybinding(w.name, gocui.KeyArrowUp, gocui.ModNone,
-		w.move(-1)); err != nil {
-		return err
-	}
-	if err := g.SetKeybinding(w.name, 'c', gocui.ModNone,
-		func(g *gocui.Gui, v *gocui.View) error {
-			w.position = 0
-			w.s.state = stateNavigate
-
-			return nil
-		}); err != nil {
-		return err
-	}
-	if err := g.SetKeybinding(w.name, gocui.KeySpace, gocui.ModNone,
+func (w *MinimapWidget) initBindings(g *gocui.Gui) error {
+	if err := g.SetKeybinding(w.name, gocui.MouseLeft, gocui.ModNone,
 		func(g *gocui.Gui, v *gocui.View) error {
-			x, y := w.game.GetCursor()
-			s, _, _ := w.game.GetStructureAt(y, x)
-			if s == nil {
-				// this should not happen, this should only display if on a structure
+			if w.s.state != stateNavigate {
 				return nil
 			}
 
-			switch f := s.(type) {
-			case *Factory:
-				f.SetRecipe(GlobalRecipeFactory.Assembly[w.position])
-				w.s.state = stateNavigate
-			}
+			// move the cursor to the center of the clicked character, the map scrolls to it
+			vx, vy := v.Cursor()
+			scale := w.game.MinimapScale(w.width - 2)
+			tx := clamp((2*vx+1)*scale, 0, len(w.game.WorldMap[0])-1)
+			ty := clamp((4*vy+2)*scale, 0, len(w.game.WorldMap)-1)
+
+			x, y := w.game.GetCursor()
+			w.game.MoveCursor(tx-x, ty-y)
 
 			return nil
 		}); err != nil {
@@ -1089,15 +3611,63 @@ func (w *RecipeSelectorWidget) initBindings(g *gocui.Gui) error {
 	return nil
 }
 
-func (w *RecipeSelectorWidget) move(d int) func(g *gocui.Gui, v *gocui.View) error {
-	return func(g *gocui.Gui, v *gocui.View) error {
-		size := len(GlobalRecipeFactory.Assembly)
+// LegendRows the number of rows of the LegendWidget, the Product-s are listed in columns
+const LegendRows int = 12
 
-		newPosition := w.position + d
-		if newPosition >= 0 && newPosition < size {
-			w.position = newPosition
-		}
+// LegendWidget a GameWidget that lists the glyph of every Product in the current symbol set, in its color
+type LegendWidget struct {
+	name string
+
+	game *Game
+	s    *state
+}
+
+func newLegendWidget() *LegendWidget {
+	w := new(LegendWidget)
 
+	return w
+}
+
+// SetGame sets the Game associated with LegendWidget
+func (w *LegendWidget) SetGame(game *Game) {
+	w.game = game
+}
+
+// Layout displays the LegendWidget in the top left corner of the map, in as many columns as needed
+func (w *LegendWidget) Layout(g *gocui.Gui) error {
+	if !w.s.legend || w.s.state == stateOverview {
 		return nil
 	}
+
+	const columnWidth = 14
+
+	_, maxY := g.Size()
+	products := GlobalProductFactory.cannonicalOrder
+
+	rows := clamp(LegendRows, 1, maxY-4)
+	columns := (len(products) + rows - 1) / rows
+
+	v, err := g.SetView(w.name, 0, 0, columns*columnWidth+1, rows+1)
+	if err != nil && err != gocui.ErrUnknownView {
+		return err
+	}
+
+	if _, err := g.SetViewOnTop(w.name); err != nil {
+		return err
+	}
+
+	v.Title = fmt.Sprintf("%s - %s", w.name, GlobalKeymap.Help(CommandLegend))
+	v.Clear()
+
+	m := GlobalDisplayConfigManager
+	symbolSet := m.GetSymbolConfig().Name
+	for i := 0; i < rows; i++ {
+		for j := i; j < len(products); j += rows {
+			p := products[j]
+			fmt.Fprintf(v, "%s %-*s", m.ProductSymbol(p, symbolSet), columnWidth-2, p.name)
+		}
+		fmt.Fprintln(v)
+	}
+
+	return nil
 }
@@ -0,0 +1,270 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/jroimartin/gocui"
+)
+
+// helpKey a line of the help, the keys are rendered from the GlobalKeymap
+type helpKey struct {
+	label string
+	keys  string
+}
+
+// helpSection documents one state of the GameWindow
+type helpSection struct {
+	title string
+	text  string
+	keys  []helpKey
+}
+
+// helpSections returns the documentation of every state, with the keys currently bound
+func helpSections() []helpSection {
+	k := GlobalKeymap
+	moves := k.Help(CommandUp, CommandLeft, CommandDown, CommandRight)
+
+	return []helpSection{
+		{"Navigate", "Move the cursor over the map, the info panel describes the tile under it.", []helpKey{
+			{"move", moves},
+			{"move by a screen", k.Help(CommandScreenUp, CommandScreenLeft, CommandScreenDown, CommandScreenRight)},
+			{"stop at next edge", k.Help(CommandJump)},
+			{"repeat a move", "type a count first"},
+			{"add a structure", k.Help(CommandAdd)},
+			{"delete", k.Help(CommandDelete)},
+			{"chest transfer", k.Help(CommandTransfer)},
+			{"extractor resource", k.Help(CommandFilter)},
+			{"chest requests", k.Help(CommandRequests)},
+			{"wire", k.Help(CommandWire)},
+			{"circuit condition", k.Help(CommandCircuit)},
+			{"place a train", k.Help(CommandTrain)},
+			{"train schedule", k.Help(CommandSchedule)},
+			{"overlays", k.Help(CommandOverlay)},
+			{"undo, redo", k.Help(CommandUndo, CommandRedo)},
+			{"statistics", k.Help(CommandStatistics)},
+			{"alerts, next alert", k.Help(CommandAlerts, CommandNextAlert)},
+			{"minimap, overview", k.Help(CommandMinimap, CommandOverview)},
+			{"product legend", k.Help(CommandLegend)},
+			{"help", k.Help(CommandHelp)},
+			{"main menu", "⌫"},
+		}},
+		{"Choose structure", "Pick the structure to build from the inventory.", []helpKey{
+			{"navigate", "↑↓"},
+			{"select", k.Help(CommandConfirm)},
+			{"cancel", k.Help(CommandCancel)},
+		}},
+		{"Place structure", "The ghost follows the cursor, it is red where it cannot be built.", []helpKey{
+			{"move", moves},
+			{"rotate", k.Help(CommandRotateLeft, CommandRotateRight)},
+			{"place", k.Help(CommandConfirm)},
+			{"cancel", k.Help(CommandCancel)},
+		}},
+		{"Choose recipe", "A new factory asks for the recipe it produces.", []helpKey{
+			{"navigate", "↑↓"},
+			{"select", k.Help(CommandConfirm)},
+			{"cancel", k.Help(CommandCancel)},
+		}},
+		{"Chest transfer", "Move products between the inventory and the chest.", []helpKey{
+			{"navigate", "↑↓"},
+			{"transfer one", k.Help(CommandConfirm)},
+			{"switch direction", k.Help(CommandTransfer)},
+			{"delete one", k.Help(CommandDelete)},
+			{"close", k.Help(CommandCancel)},
+		}},
+		{"Drag belts", "The belts follow the path of the cursor, moving back shortens it.", []helpKey{
+			{"path", moves},
+			{"place", k.Help(CommandConfirm)},
+			{"cancel", k.Help(CommandCancel)},
+		}},
+		{"Route belts", "Choose the target, the route avoids the structures and goes under obstacles.", []helpKey{
+			{"move", moves},
+			{"route, build", k.Help(CommandConfirm)},
+			{"cancel", k.Help(CommandCancel)},
+		}},
+		{"Copy area", "Select an area to save as a blueprint, then paste it.", []helpKey{
+			{"resize", moves},
+			{"capture, place", k.Help(CommandConfirm)},
+			{"rotate", k.Help(CommandRotateLeft, CommandRotateRight)},
+			{"cancel", k.Help(CommandCancel)},
+		}},
+		{"Blueprint library", "The saved blueprints, kept between games.", []helpKey{
+			{"navigate", "↑↓"},
+			{"paste", k.Help(CommandConfirm)},
+			{"delete", k.Help(CommandRemove)},
+			{"export, import", k.Help(CommandExport, CommandImport)},
+			{"close", k.Help(CommandCancel)},
+		}},
+		{"Share blueprint", "Export shows the text of the blueprint to copy, import reads a pasted text.", []helpKey{
+			{"close export", k.Help(CommandCancel)},
+			{"add import", "↵"},
+			{"close import", "empty ↵"},
+		}},
+		{"Clear area", "Select an area, its structures return to the inventory.", []helpKey{
+			{"resize", moves},
+			{"select", k.Help(CommandConfirm)},
+			{"confirm", k.Help(CommandAccept)},
+			{"overflow policy", k.Help(CommandPolicy)},
+			{"cancel", k.Help(CommandDecline, CommandCancel)},
+		}},
+		{"Train schedule", "The stations visited by the train, in order.", []helpKey{
+			{"navigate", "↑↓"},
+			{"station", "←→"},
+			{"add", k.Help(CommandAdd)},
+			{"load, unload", k.Help(CommandLoad)},
+			{"delete", k.Help(CommandDelete)},
+			{"close", k.Help(CommandCancel)},
+		}},
+		{"Chest requests", "The products a requester chest asks the logistic bots for.", []helpKey{
+			{"navigate", "↑↓"},
+			{"change", "←→"},
+			{"clear", k.Help(CommandRemove)},
+			{"close", k.Help(CommandCancel)},
+		}},
+		{"Circuit", "The condition enabling the structure, or the combinator settings.", []helpKey{
+			{"navigate", "↑↓"},
+			{"change", "←→"},
+			{"by ten", k.Help(CommandDecrease, CommandIncrease)},
+			{"remove", k.Help(CommandRemove)},
+			{"close", k.Help(CommandCancel)},
+		}},
+		{"Overview", "The whole map zoomed out, the cursor pans it.", []helpKey{
+			{"pan", moves},
+			{"jump", k.Help(CommandConfirm)},
+			{"cancel", k.Help(CommandCancel)},
+		}},
+		{"Mouse", "Left click moves the cursor or places the ghost, right click deletes, the wheel scrolls.", nil},
+	}
+}
+
+// HelpWindow a Window that documents every state of the game
+type HelpWindow struct {
+	manager WindowManager
+	back    Window
+	widgets []Widget
+}
+
+// NewHelpWindow creates a new HelpWindow
+func NewHelpWindow(manager WindowManager) *HelpWindow {
+	var w HelpWindow
+	w.manager = manager
+
+	w.widgets = append(w.widgets, &HelpWidget{name: "Help", window: &w})
+
+	return &w
+}
+
+// Show displays the HelpWindow, closing it returns to the Window it was opened from
+func (w *HelpWindow) Show(back Window) {
+	w.back = back
+	w.manager.SetTopWindow(w)
+}
+
+// Layout displays the HelpWindow
+func (w *HelpWindow) Layout(g *gocui.Gui) error {
+	maxX, maxY := g.Size()
+
+	g.Cursor = false
+
+	v, err := g.SetView("HelpWindow", 0, 0, maxX-1, maxY-1)
+	if err == nil || err == gocui.ErrUnknownView {
+		if _, err := g.SetViewOnTop("HelpWindow"); err != nil {
+			return err
+		}
+
+		v.Title = "Help"
+	}
+
+	for _, widget := range w.widgets {
+		widget.Layout(g)
+	}
+
+	return nil
+}
+
+// HelpWidget displays the help sections, scrolled with the arrows
+type HelpWidget struct {
+	name   string
+	window *HelpWindow
+	offset int
+}
+
+// Layout displays the HelpWidget
+func (w *HelpWidget) Layout(g *gocui.Gui) error {
+	maxX, maxY := g.Size()
+
+	v, err := g.SetView(w.name, 1, 1, maxX-2, maxY-2)
+	if err != nil && err != gocui.ErrUnknownView {
+		return err
+	}
+
+	if err == gocui.ErrUnknownView {
+		v.Wrap = true
+		if err := w.initBindings(g); err != nil {
+			return err
+		}
+	}
+
+	if _, err := g.SetCurrentView(w.name); err != nil {
+		return err
+	}
+
+	if _, err := g.SetViewOnTop(w.name); err != nil {
+		return err
+	}
+
+	v.Title = fmt.Sprintf("scroll: ↑↓  close: %s", GlobalKeymap.Help(CommandCancel))
+	v.Clear()
+
+	lines := make([]string, 0)
+	for _, section := range helpSections() {
+		lines = append(lines, fmt.Sprintf("\033[1m%s\033[0m", section.title))
+		lines = append(lines, "  "+section.text)
+		for _, key := range section.keys {
+			lines = append(lines, fmt.Sprintf("  %-20s %s", key.label, key.keys))
+		}
+		lines = append(lines, "")
+	}
+
+	if w.offset > len(lines)-1 {
+		w.offset = len(lines) - 1
+	}
+
+	for _, line := range lines[w.offset:] {
+		fmt.Fprintln(v, line)
+	}
+
+	return nil
+}
+
+func (w *HelpWidget) initBindings(g *gocui.Gui) error {
+	if err := g.SetKeybinding(w.name, gocui.KeyArrowUp, gocui.ModNone,
+		func(g *gocui.Gui, v *gocui.View) error {
+			if w.offset > 0 {
+				w.offset--
+			}
+
+			return nil
+		}); err != nil {
+		return err
+	}
+	if err := g.SetKeybinding(w.name, gocui.KeyArrowDown, gocui.ModNone,
+		func(g *gocui.Gui, v *gocui.View) error {
+			w.offset++
+
+			return nil
+		}); err != nil {
+		return err
+	}
+	for _, c := range []Command{CommandCancel, CommandHelp} {
+		if err := GlobalKeymap.SetKeybinding(g, w.name, c,
+			func(g *gocui.Gui, v *gocui.View) error {
+				w.window.manager.SetTopWindow(w.window.back)
+
+				return nil
+			}); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,452 @@
+package main
+
+// HistoryMaxActions the maximum number of actions that can be undone
+const HistoryMaxActions int = 100
+
+// Action a recorded change of the Game that can be reverted and applied again
+type Action interface {
+	Undo(g *Game) bool
+	Redo(g *Game) bool
+}
+
+// History the actions done by the player, in order, and the ones undone
+type History struct {
+	done   []Action
+	undone []Action
+}
+
+// NewHistory creates a new *History
+func NewHistory() *History {
+	h := new(History)
+	h.done = make([]Action, 0)
+	h.undone = make([]Action, 0)
+
+	return h
+}
+
+// CanUndo indicates if there is an Action to be undone
+func (h *History) CanUndo() bool {
+	return len(h.done) != 0
+}
+
+// CanRedo indicates if there is an undone Action to be applied again
+func (h *History) CanRedo() bool {
+	return len(h.undone) != 0
+}
+
+func (h *History) record(a Action) {
+	h.done = append(h.done, a)
+	if len(h.done) > HistoryMaxActions {
+		h.done = h.done[1:]
+	}
+
+	h.undone = h.undone[:0]
+}
+
+// Undo reverts the last Action of the History
+func (g *Game) Undo() bool {
+	h := g.history
+	if !h.CanUndo() {
+		return false
+	}
+
+	a := h.done[len(h.done)-1]
+	if !a.Undo(g) {
+		return false
+	}
+
+	h.done = h.done[:len(h.done)-1]
+	h.undone = append(h.undone, a)
+
+	return true
+}
+
+// Redo applies again the last undone Action of the History
+func (g *Game) Redo() bool {
+	h := g.history
+	if !h.CanRedo() {
+		return false
+	}
+
+	a := h.undone[len(h.undone)-1]
+	if !a.Redo(g) {
+		return false
+	}
+
+	h.undone = h.undone[:len(h.undone)-1]
+	h.done = append(h.done, a)
+
+	return true
+}
+
+// History returns the actions recorded for the Game
+func (g *Game) History() *History {
+	return g.history
+}
+
+// placeAction a Structure built from the inventory, undoing it returns the Structure and the contents it gathered
+type placeAction struct {
+	y, x int
+	s    Structure
+
+	// contents the Product-s moved to the inventory by the last Undo
+	contents map[*Product]int
+}
+
+func (a *placeAction) Undo(g *Game) bool {
+	if s, _, _ := g.GetStructureAt(a.y, a.x); s != a.s {
+		return false
+	}
+
+	contents := structureContents(a.s)
+
+	total := 0
+	for _, c := range contents {
+		total += c
+	}
+
+	if g.freeForRemoval(a.s) < 1+total || g.underTrain(a.s, a.y, a.x) {
+		return false
+	}
+
+	g.RemoveStructure(a.y, a.x)
+	g.inventory.Add(GlobalProductFactory.GetProduct(a.s.GetCode()), 1)
+
+	clearContents(a.s)
+	for p, c := range contents {
+		g.inventory.Add(p, c)
+	}
+	a.contents = contents
+
+	return true
+}
+
+func (a *placeAction) Redo(g *Game) bool {
+	product := GlobalProductFactory.GetProduct(a.s.GetCode())
+	if g.inventory.objects[product] == 0 {
+		return false
+	}
+
+	for p, c := range a.contents {
+		if p == product {
+			c++
+		}
+		if g.inventory.objects[p] < c {
+			return false
+		}
+	}
+
+	if !g.PlaceStructure(a.y, a.x, a.s) {
+		return false
+	}
+	g.inventory.Remove(product, 1)
+
+	for p, c := range a.contents {
+		g.inventory.Remove(p, c)
+	}
+	restoreContents(a.s, a.contents)
+	a.contents = nil
+
+	return true
+}
+
+// removeAction a Structure returned to the inventory with its contents, with the signal wires it had; the
+// contents that do not fit in the inventory stop the removal, or are discarded
+type removeAction struct {
+	y, x      int
+	s         Structure
+	wires     []Structure
+	condition *CircuitCondition
+	discard   bool
+
+	// contents the Product-s moved to the inventory by the last Redo, discarded the ones lost
+	contents  map[*Product]int
+	discarded int
+}
+
+func (a *removeAction) Undo(g *Game) bool {
+	product := GlobalProductFactory.GetProduct(a.s.GetCode())
+	wire := GlobalProductFactory.GetProduct(ProductProcessedCopperWire)
+
+	if g.inventory.objects[product] == 0 || g.inventory.objects[wire] < len(a.wires) {
+		return false
+	}
+
+	for p, c := range a.contents {
+		if g.inventory.objects[p] < c+a.required(p, product, wire) {
+			return false
+		}
+	}
+
+	if !g.PlaceStructure(a.y, a.x, a.s) {
+		return false
+	}
+	g.inventory.Remove(product, 1)
+
+	for p, c := range a.contents {
+		g.inventory.Remove(p, c)
+	}
+	restoreContents(a.s, a.contents)
+
+	for _, other := range a.wires {
+		g.ToggleWire(a.s, other)
+	}
+	g.SetCircuitCondition(a.s, a.condition)
+
+	return true
+}
+
+func (a *removeAction) Redo(g *Game) bool {
+	if s, _, _ := g.GetStructureAt(a.y, a.x); s != a.s {
+		return false
+	}
+
+	// the contents may have changed since the Action was recorded
+	contents := structureContents(a.s)
+
+	total := 0
+	for _, c := range contents {
+		total += c
+	}
+
+	free := g.freeForRemoval(a.s)
+	if free < 1 || (!a.discard && free < 1+total) || g.underTrain(a.s, a.y, a.x) {
+		return false
+	}
+
+	g.RemoveStructure(a.y, a.x)
+	g.inventory.Add(GlobalProductFactory.GetProduct(a.s.GetCode()), 1)
+
+	clearContents(a.s)
+	a.contents = make(map[*Product]int)
+	a.discarded = 0
+	for _, p := range GlobalProductFactory.cannonicalOrder {
+		c, present := contents[p]
+		if !present {
+			continue
+		}
+
+		added := g.inventory.Add(p, c)
+		a.contents[p] = added
+		a.discarded += c - added
+	}
+
+	return true
+}
+
+// required returns how many of the Product the Undo needs besides the contents
+func (a *removeAction) required(p, product, wire *Product) int {
+	count := 0
+	if p == product {
+		count++
+	}
+	if p == wire {
+		count += len(a.wires)
+	}
+
+	return count
+}
+
+// recipeAction a change of the Recipe used by a Factory, its Product-s are exchanged with the inventory
+type recipeAction struct {
+	f      *Factory
+	before *Recipe
+	after  *Recipe
+
+	// contents the Product-s of the before Recipe moved to the inventory by the last Redo, with the progress
+	// and the Fluid of the Factory
+	contents map[*Product]int
+	counter  int
+	fluid    int
+}
+
+func (a *recipeAction) Undo(g *Game) bool {
+	if !g.swapRecipe(a.f, a.before, a.contents, a.counter) {
+		return false
+	}
+	a.f.box.Add(a.f.box.filter, a.fluid)
+
+	return true
+}
+
+func (a *recipeAction) Redo(g *Game) bool {
+	contents, counter, fluid := structureContents(a.f), a.f.counter, a.f.box.Amount()
+	if !g.swapRecipe(a.f, a.after, nil, 0) {
+		return false
+	}
+
+	a.contents, a.counter, a.fluid = contents, counter, fluid
+
+	return true
+}
+
+// swapRecipe sets the Recipe of the Factory, moving its Product-s to the inventory and putting back the restored
+// ones taken from the inventory with the production progress; nothing changes if the inventory cannot hold them
+func (g *Game) swapRecipe(f *Factory, r *Recipe, restore map[*Product]int, counter int) bool {
+	total := 0
+	for p, c := range restore {
+		if g.inventory.objects[p] < c {
+			return false
+		}
+		total -= c
+	}
+
+	contents := structureContents(f)
+	for _, c := range contents {
+		total += c
+	}
+
+	if g.inventory.Size()+total > g.inventory.Capacity() {
+		return false
+	}
+
+	for p, c := range restore {
+		g.inventory.Remove(p, c)
+	}
+	for p, c := range contents {
+		g.inventory.Add(p, c)
+	}
+
+	f.SetRecipe(r)
+	if r != nil && counter < r.productionTicks {
+		f.counter = counter
+	}
+	restoreContents(f, restore)
+
+	return true
+}
+
+// transferAction Product-s moved between two Storage-s, a nil target means they were discarded
+type transferAction struct {
+	from, to *Storage
+	p        *Product
+	count    int
+}
+
+func (a *transferAction) Undo(g *Game) bool {
+	return moveProducts(a.to, a.from, a.p, a.count)
+}
+
+func (a *transferAction) Redo(g *Game) bool {
+	return moveProducts(a.from, a.to, a.p, a.count)
+}
+
+// moveProducts moves all the Product-s or none, a nil Storage is an infinite source or sink
+func moveProducts(from, to *Storage, p *Product, count int) bool {
+	if from != nil && from.objects[p] < count {
+		return false
+	}
+
+	if to != nil && to.Capacity()-to.Size() < count {
+		return false
+	}
+
+	if from != nil {
+		from.Remove(p, count)
+	}
+	if to != nil {
+		to.Add(p, count)
+	}
+
+	return true
+}
+
+// groupAction several actions done at once, they are undone in reverse order
+type groupAction []Action
+
+func (a groupAction) Undo(g *Game) bool {
+	for i := len(a) - 1; i >= 0; i-- {
+		if !a[i].Undo(g) {
+			// restore the actions already undone, the group is undone as a whole
+			for j := i + 1; j < len(a); j++ {
+				a[j].Redo(g)
+			}
+			return false
+		}
+	}
+
+	return true
+}
+
+func (a groupAction) Redo(g *Game) bool {
+	for i, action := range a {
+		if !action.Redo(g) {
+			for j := i - 1; j >= 0; j-- {
+				a[j].Undo(g)
+			}
+			return false
+		}
+	}
+
+	return true
+}
+
+// buildStructure places the Structure taking it from the inventory, returning the Action to be recorded
+func (g *Game) buildStructure(y, x int, s Structure) Action {
+	a := &placeAction{y, x, s, nil}
+	if !a.Redo(g) {
+		return nil
+	}
+
+	return a
+}
+
+// BuildStructure places the Structure taking it from the inventory and records the Action
+func (g *Game) BuildStructure(y, x int, s Structure) bool {
+	a := g.buildStructure(y, x, s)
+	if a == nil {
+		return false
+	}
+
+	g.history.record(a)
+
+	return true
+}
+
+// DeconstructStructure removes the Structure at the location returning it and its contents to the inventory and
+// records the Action
+func (g *Game) DeconstructStructure(y, x int) Structure {
+	s, sy, sx := g.GetStructureAt(y, x)
+	if s == nil {
+		return nil
+	}
+
+	wires := make([]Structure, 0, len(g.wires[s]))
+	for other := range g.wires[s] {
+		wires = append(wires, other)
+	}
+
+	a := &removeAction{sy, sx, s, wires, g.conditions[s], false, nil, 0}
+	if !a.Redo(g) {
+		return nil
+	}
+
+	g.history.record(a)
+
+	return s
+}
+
+// ChangeRecipe specifies the Recipe used by the Factory, moving its Product-s to the inventory, and records the
+// Action
+func (g *Game) ChangeRecipe(f *Factory, r *Recipe) bool {
+	a := &recipeAction{f, f.recipe, r, nil, 0, 0}
+	if !a.Redo(g) {
+		return false
+	}
+
+	g.history.record(a)
+
+	return true
+}
+
+// TransferProducts moves Product-s between two Storage-s and records the Action, a nil target discards them
+func (g *Game) TransferProducts(from, to *Storage, p *Product, count int) bool {
+	a := &transferAction{from, to, p, count}
+	if !a.Redo(g) {
+		return false
+	}
+
+	g.history.record(a)
+
+	return true
+}
@@ -0,0 +1,683 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/jroimartin/gocui"
+)
+
+// CountMax the largest count prefix of a move on the map
+const CountMax int = 999
+
+// Command a command of the game that can be bound to keys
+type Command int
+
+const (
+	// CommandUp moves the cursor up
+	CommandUp Command = iota
+	// CommandDown moves the cursor down
+	CommandDown
+	// CommandLeft moves the cursor left
+	CommandLeft
+	// CommandRight moves the cursor right
+	CommandRight
+	// CommandScreenUp moves the cursor up by a screen
+	CommandScreenUp
+	// CommandScreenDown moves the cursor down by a screen
+	CommandScreenDown
+	// CommandScreenLeft moves the cursor left by a screen
+	CommandScreenLeft
+	// CommandScreenRight moves the cursor right by a screen
+	CommandScreenRight
+	// CommandJump makes the next move stop at the edge of a Structure or of a resource patch
+	CommandJump
+	// CommandConfirm places, selects or transfers, depending on the state
+	CommandConfirm
+	// CommandCancel leaves the current state or closes the panel
+	CommandCancel
+	// CommandAdd chooses a Structure to build or adds a schedule entry
+	CommandAdd
+	// CommandDelete removes the Structure, the Product or the schedule entry
+	CommandDelete
+	// CommandRotateLeft rotates the ghost counterclockwise
+	CommandRotateLeft
+	// CommandRotateRight rotates the ghost clockwise
+	CommandRotateRight
+	// CommandTransfer opens the Chest transfer or switches its direction
+	CommandTransfer
+	// CommandFilter changes the resource mined by an Extractor
+	CommandFilter
+	// CommandRequests edits the requests of a requester Chest
+	CommandRequests
+	// CommandWire connects two Structure-s to the same CircuitNetwork
+	CommandWire
+	// CommandCircuit edits the circuit condition of a Structure
+	CommandCircuit
+	// CommandTrain places a Train at a Station
+	CommandTrain
+	// CommandSchedule edits the schedule of a Train
+	CommandSchedule
+	// CommandOverlay cycles the map overlays
+	CommandOverlay
+	// CommandBelts drags a line of belts
+	CommandBelts
+	// CommandCopy captures an area as a Blueprint
+	CommandCopy
+	// CommandClear deconstructs an area
+	CommandClear
+	// CommandLibrary opens the Blueprint library
+	CommandLibrary
+	// CommandRoute routes belts from the Structure
+	CommandRoute
+	// CommandUndo reverts the last change of the map
+	CommandUndo
+	// CommandRedo applies the last reverted change of the map
+	CommandRedo
+	// CommandStatistics opens the production statistics
+	CommandStatistics
+	// CommandAlerts collapses or expands the alerts
+	CommandAlerts
+	// CommandNextAlert jumps to the next alert
+	CommandNextAlert
+	// CommandMinimap shows or hides the minimap
+	CommandMinimap
+	// CommandOverview zooms the map out
+	CommandOverview
+	// CommandLegend shows or hides the legend of the Product glyphs
+	CommandLegend
+	// CommandRemove removes the selected entry of a panel
+	CommandRemove
+	// CommandExport shows the text of the selected Blueprint, to share it
+	CommandExport
+	// CommandImport adds a Blueprint from a shared text
+	CommandImport
+	// CommandLoad switches between loading and unloading at a Station
+	CommandLoad
+	// CommandDecrease decreases the selected value by ten
+	CommandDecrease
+	// CommandIncrease increases the selected value by ten
+	CommandIncrease
+	// CommandAccept confirms the deconstruction
+	CommandAccept
+	// CommandDecline cancels the deconstruction
+	CommandDecline
+	// CommandPolicy cycles the overflow policy of the deconstruction
+	CommandPolicy
+	// CommandNewGame starts a new game
+	CommandNewGame
+	// CommandContinue returns to the running game
+	CommandContinue
+	// CommandSettings opens the settings
+	CommandSettings
+	// CommandCalculator opens the ratio calculator
+	CommandCalculator
+	// CommandQuit exits the game
+	CommandQuit
+	// CommandHelp opens or closes the help
+	CommandHelp
+	// CommandTutorial starts a new game with the tutorial
+	CommandTutorial
+	commandCount
+)
+
+// the views in which the commands are bound, two commands sharing a context cannot share a key
+const (
+	keyContextMap = 1 << iota
+	keyContextMenu
+	keyContextSelector
+	keyContextInventory
+	keyContextRecipes
+	keyContextSchedule
+	keyContextRequests
+	keyContextCircuit
+	keyContextLibrary
+	keyContextShare
+	keyContextDeconstruct
+	keyContextCalculator
+	keyContextStatistics
+	keyContextHelp
+)
+
+// navigationKeys the keys bound directly by the views of the contexts to move their selection, they cannot be
+// bound to a Command used in the same context
+var navigationKeys = []struct {
+	contexts int
+	keys     []string
+}{
+	{keyContextSelector | keyContextInventory | keyContextRecipes | keyContextLibrary | keyContextHelp,
+		[]string{"up", "down"}},
+	{keyContextSchedule | keyContextRequests | keyContextCircuit | keyContextCalculator | keyContextStatistics,
+		[]string{"up", "down", "left", "right"}},
+}
+
+// commandInfo describes a Command: the name used in the keymap file, the views it is bound in and its default keys
+type commandInfo struct {
+	name     string
+	contexts int
+	keys     []string
+}
+
+var commandInfos = []commandInfo{
+	CommandUp:          {"up", keyContextMap, []string{"up"}},
+	CommandDown:        {"down", keyContextMap, []string{"down"}},
+	CommandLeft:        {"left", keyContextMap, []string{"left"}},
+	CommandRight:       {"right", keyContextMap, []string{"right"}},
+	CommandScreenUp:    {"screen-up", keyContextMap, []string{"pgup"}},
+	CommandScreenDown:  {"screen-down", keyContextMap, []string{"pgdn"}},
+	CommandScreenLeft:  {"screen-left", keyContextMap, []string{"home"}},
+	CommandScreenRight: {"screen-right", keyContextMap, []string{"end"}},
+	CommandJump:        {"jump", keyContextMap, []string{"."}},
+	CommandConfirm:     {"confirm", keyContextMap | keyContextSelector | keyContextInventory | keyContextRecipes | keyContextLibrary, []string{"space"}},
+	CommandCancel:      {"cancel", keyContextMap | keyContextSelector | keyContextInventory | keyContextRecipes | keyContextSchedule | keyContextRequests | keyContextCircuit | keyContextLibrary | keyContextShare | keyContextDeconstruct | keyContextStatistics | keyContextHelp, []string{"c"}},
+	CommandAdd:         {"add", keyContextMap | keyContextSchedule, []string{"a"}},
+	CommandDelete:      {"delete", keyContextMap | keyContextInventory | keyContextSchedule, []string{"d"}},
+	CommandRotateLeft:  {"rotate-left", keyContextMap, []string{"q"}},
+	CommandRotateRight: {"rotate-right", keyContextMap, []string{"e"}},
+	CommandTransfer:    {"transfer", keyContextMap | keyContextInventory, []string{"t"}},
+	CommandFilter:      {"filter", keyContextMap, []string{"f"}},
+	CommandRequests:    {"requests", keyContextMap, []string{"r"}},
+	CommandWire:        {"wire", keyContextMap, []string{"w"}},
+	CommandCircuit:     {"circuit", keyContextMap, []string{"k"}},
+	CommandTrain:       {"train", keyContextMap, []string{"n"}},
+	CommandSchedule:    {"schedule", keyContextMap, []string{"o"}},
+	CommandOverlay:     {"overlay", keyContextMap, []string{"v"}},
+	CommandBelts:       {"belts", keyContextMap, []string{"g"}},
+	CommandCopy:        {"copy", keyContextMap, []string{"b"}},
+	CommandClear:       {"clear", keyContextMap, []string{"x"}},
+	CommandLibrary:     {"library", keyContextMap, []string{"l"}},
+	CommandRoute:       {"route", keyContextMap, []string{"p"}},
+	CommandUndo:        {"undo", keyContextMap, []string{"u"}},
+	CommandRedo:        {"redo", keyContextMap, []string{"U"}},
+	CommandStatistics:  {"statistics", keyContextMap, []string{"s"}},
+	CommandAlerts:      {"alerts", keyContextMap, []string{"!"}},
+	CommandNextAlert:   {"next-alert", keyContextMap, []string{"j"}},
+	CommandMinimap:     {"minimap", keyContextMap, []string{"m"}},
+	CommandOverview:    {"overview", keyContextMap, []string{"z"}},
+	CommandLegend:      {"legend", keyContextMap, []string{"G"}},
+	CommandRemove:      {"remove", keyContextRequests | keyContextCircuit | keyContextLibrary, []string{"x"}},
+	CommandExport:      {"export", keyContextLibrary, []string{"e"}},
+	CommandImport:      {"import", keyContextLibrary, []string{"i"}},
+	CommandLoad:        {"load", keyContextSchedule, []string{"l"}},
+	CommandDecrease:    {"decrease", keyContextCircuit | keyContextCalculator, []string{"["}},
+	CommandIncrease:    {"increase", keyContextCircuit | keyContextCalculator, []string{"]"}},
+	CommandAccept:      {"accept", keyContextDeconstruct, []string{"y"}},
+	CommandDecline:     {"decline", keyContextDeconstruct, []string{"n"}},
+	CommandPolicy:      {"policy", keyContextDeconstruct, []string{"p"}},
+	CommandNewGame:     {"new-game", keyContextMenu, []string{"n"}},
+	CommandContinue:    {"continue", keyContextMenu, []string{"c"}},
+	CommandSettings:    {"settings", keyContextMenu, []string{"s"}},
+	CommandCalculator:  {"calculator", keyContextMenu, []string{"r"}},
+	CommandQuit:        {"quit", keyContextMenu, []string{"q"}},
+	CommandHelp:        {"help", keyContextMap | keyContextMenu | keyContextHelp, []string{"?"}},
+	CommandTutorial:    {"tutorial", keyContextMenu, []string{"t"}},
+}
+
+func (a Command) String() string {
+	return commandInfos[a].name
+}
+
+// namedKey a special key that can be bound, with the name used in the keymap file and its symbol in the help
+type namedKey struct {
+	name   string
+	symbol string
+	key    gocui.Key
+}
+
+var namedKeys = []namedKey{
+	{"space", "˽", gocui.KeySpace},
+	{"up", "↑", gocui.KeyArrowUp},
+	{"down", "↓", gocui.KeyArrowDown},
+	{"left", "←", gocui.KeyArrowLeft},
+	{"right", "→", gocui.KeyArrowRight},
+	{"tab", "⇥", gocui.KeyTab},
+	{"enter", "↵", gocui.KeyEnter},
+	{"pgup", "⇞", gocui.KeyPgup},
+	{"pgdn", "⇟", gocui.KeyPgdn},
+	{"home", "⇱", gocui.KeyHome},
+	{"end", "⇲", gocui.KeyEnd},
+	{"insert", "ins", gocui.KeyInsert},
+	{"delete", "del", gocui.KeyDelete},
+}
+
+// Key a key that can be bound to a Command, either a special key or a printable character
+type Key struct {
+	key gocui.Key
+	ch  rune
+}
+
+// ParseKey returns the Key for the name of a special key or a single character
+func ParseKey(name string) (Key, error) {
+	if runes := []rune(name); len(runes) == 1 && runes[0] > ' ' {
+		return Key{ch: runes[0]}, nil
+	}
+
+	for _, k := range namedKeys {
+		if k.name == name {
+			return Key{key: k.key}, nil
+		}
+	}
+
+	return Key{}, fmt.Errorf("unknown key %q", name)
+}
+
+// newKey returns the Key of a key press, false if the key cannot be bound
+func newKey(key gocui.Key, ch rune) (Key, bool) {
+	if ch != 0 {
+		return Key{ch: ch}, ch > ' '
+	}
+
+	for _, k := range namedKeys {
+		if k.key == key {
+			return Key{key: key}, true
+		}
+	}
+
+	return Key{}, false
+}
+
+func (k Key) named() namedKey {
+	for _, n := range namedKeys {
+		if n.key == k.key {
+			return n
+		}
+	}
+
+	return namedKey{}
+}
+
+// String returns the name of the Key, as used in the keymap file
+func (k Key) String() string {
+	if k.ch != 0 {
+		return string(k.ch)
+	}
+
+	return k.named().name
+}
+
+// Symbol returns the short representation of the Key for the help
+func (k Key) Symbol() string {
+	if k.ch != 0 {
+		return string(k.ch)
+	}
+
+	return k.named().symbol
+}
+
+// binding returns the Key as expected by gocui.Gui.SetKeybinding
+func (k Key) binding() interface{} {
+	if k.ch != 0 {
+		return k.ch
+	}
+
+	return k.key
+}
+
+// commandHandler a handler bound to the keys of a Command in a view
+type commandHandler struct {
+	view    string
+	command Command
+	handler func(*gocui.Gui, *gocui.View) error
+}
+
+// GlobalKeymap contains the keys bound to each Command
+var GlobalKeymap = NewKeymap()
+
+// Keymap stores the keys bound to each Command and the handlers registered for them
+type Keymap struct {
+	keys     [][]Key
+	handlers []commandHandler
+}
+
+// NewKeymap creates a new *Keymap with the default keys
+func NewKeymap() *Keymap {
+	k := new(Keymap)
+	k.keys = make([][]Key, commandCount)
+	for a, info := range commandInfos {
+		for _, name := range info.keys {
+			key, err := ParseKey(name)
+			if err != nil {
+				panic(err)
+			}
+
+			k.keys[a] = append(k.keys[a], key)
+		}
+	}
+
+	return k
+}
+
+// Keys returns the keys bound to the Command
+func (k *Keymap) Keys(a Command) []Key {
+	return k.keys[a]
+}
+
+// Help returns the symbols of the first key of each Command, as displayed in the help
+func (k *Keymap) Help(commands ...Command) string {
+	var b strings.Builder
+	for _, a := range commands {
+		if keys := k.keys[a]; len(keys) != 0 {
+			b.WriteString(keys[0].Symbol())
+		}
+	}
+
+	return b.String()
+}
+
+// Conflicts returns an error for the first key bound to two commands used in the same view, the digits are
+// reserved on the map for the count prefixes and the navigationKeys in the views moving a selection
+func (k *Keymap) Conflicts() error {
+	for a := Command(0); a < commandCount; a++ {
+		if commandInfos[a].contexts&keyContextMap == 0 {
+			continue
+		}
+
+		for _, key := range k.keys[a] {
+			if key.ch >= '0' && key.ch <= '9' {
+				return fmt.Errorf("key %s is reserved for counts", key)
+			}
+		}
+	}
+
+	for a := Command(0); a < commandCount; a++ {
+		for _, reserved := range navigationKeys {
+			if commandInfos[a].contexts&reserved.contexts == 0 {
+				continue
+			}
+
+			for _, key := range k.keys[a] {
+				for _, name := range reserved.keys {
+					if key.String() == name {
+						return fmt.Errorf("key %s of %s is reserved to move the selection", key, a)
+					}
+				}
+			}
+		}
+	}
+
+	for a := Command(0); a < commandCount; a++ {
+		for b := a + 1; b < commandCount; b++ {
+			if commandInfos[a].contexts&commandInfos[b].contexts == 0 {
+				continue
+			}
+
+			for _, keyA := range k.keys[a] {
+				for _, keyB := range k.keys[b] {
+					if keyA == keyB {
+						return fmt.Errorf("key %s bound to both %s and %s", keyA, a, b)
+					}
+				}
+			}
+		}
+	}
+
+	return nil
+}
+
+// SetKeybinding binds the handler to the keys of the Command in the view, the binding follows the later changes of the keys
+func (k *Keymap) SetKeybinding(g *gocui.Gui, view string, a Command, handler func(*gocui.Gui, *gocui.View) error) error {
+	k.handlers = append(k.handlers, commandHandler{view, a, handler})
+	for _, key := range k.keys[a] {
+		if err := g.SetKeybinding(view, key.binding(), gocui.ModNone, handler); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Rebind replaces the keys of the Command, it is refused if the keys conflict with another Command
+func (k *Keymap) Rebind(g *gocui.Gui, a Command, keys []Key) error {
+	return k.Apply(g, map[Command][]Key{a: keys})
+}
+
+// Apply replaces the keys of several commands at once, nothing changes if the new keys conflict
+func (k *Keymap) Apply(g *gocui.Gui, keys map[Command][]Key) error {
+	old := make(map[Command][]Key)
+	for a, newKeys := range keys {
+		old[a] = k.keys[a]
+		k.keys[a] = newKeys
+	}
+
+	if err := k.Conflicts(); err != nil {
+		for a, oldKeys := range old {
+			k.keys[a] = oldKeys
+		}
+		return err
+	}
+
+	// remove all the old bindings first, a key can move from one Command to another
+	for _, h := range k.handlers {
+		for _, key := range old[h.command] {
+			g.DeleteKeybinding(h.view, key.binding(), gocui.ModNone)
+		}
+	}
+
+	for _, h := range k.handlers {
+		if _, ok := keys[h.command]; !ok {
+			continue
+		}
+
+		for _, key := range keys[h.command] {
+			if err := g.SetKeybinding(h.view, key.binding(), gocui.ModNone, h.handler); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// KeymapPreset the movement keys of a keyboard layout, with the commands moved out of their way
+type KeymapPreset struct {
+	Name string
+	keys map[Command][]string
+}
+
+// KeymapPresets the available movement presets, the first one has the default keys
+var KeymapPresets = []KeymapPreset{
+	{"arrows", map[Command][]string{
+		CommandUp:          {"up"},
+		CommandDown:        {"down"},
+		CommandLeft:        {"left"},
+		CommandRight:       {"right"},
+		CommandScreenUp:    {"pgup"},
+		CommandScreenDown:  {"pgdn"},
+		CommandScreenLeft:  {"home"},
+		CommandScreenRight: {"end"},
+		CommandAdd:         {"a"},
+		CommandDelete:      {"d"},
+		CommandWire:        {"w"},
+		CommandStatistics:  {"s"},
+		CommandCircuit:     {"k"},
+		CommandNextAlert:   {"j"},
+		CommandLibrary:     {"l"},
+	}},
+	{"vim", map[Command][]string{
+		CommandUp:          {"up", "k"},
+		CommandDown:        {"down", "j"},
+		CommandLeft:        {"left", "h"},
+		CommandRight:       {"right", "l"},
+		CommandScreenUp:    {"pgup", "K"},
+		CommandScreenDown:  {"pgdn", "J"},
+		CommandScreenLeft:  {"home", "H"},
+		CommandScreenRight: {"end", "L"},
+		CommandAdd:         {"a"},
+		CommandDelete:      {"d"},
+		CommandWire:        {"w"},
+		CommandStatistics:  {"s"},
+		CommandCircuit:     {"i"},
+		CommandNextAlert:   {">"},
+		CommandLibrary:     {"y"},
+	}},
+	{"wasd", map[Command][]string{
+		CommandUp:          {"up", "w"},
+		CommandDown:        {"down", "s"},
+		CommandLeft:        {"left", "a"},
+		CommandRight:       {"right", "d"},
+		CommandScreenUp:    {"pgup", "W"},
+		CommandScreenDown:  {"pgdn", "S"},
+		CommandScreenLeft:  {"home", "A"},
+		CommandScreenRight: {"end", "D"},
+		CommandAdd:         {"i"},
+		CommandDelete:      {"delete"},
+		CommandWire:        {"y"},
+		CommandStatistics:  {"h"},
+		CommandCircuit:     {"k"},
+		CommandNextAlert:   {"j"},
+		CommandLibrary:     {"l"},
+	}},
+}
+
+func (p *KeymapPreset) parse() map[Command][]Key {
+	keys := make(map[Command][]Key)
+	for a, names := range p.keys {
+		for _, name := range names {
+			key, err := ParseKey(name)
+			if err != nil {
+				panic(err)
+			}
+
+			keys[a] = append(keys[a], key)
+		}
+	}
+
+	return keys
+}
+
+// ApplyPreset binds the keys of the KeymapPreset
+func (k *Keymap) ApplyPreset(g *gocui.Gui, p *KeymapPreset) error {
+	return k.Apply(g, p.parse())
+}
+
+// Preset returns the index of the KeymapPreset matching the keys, -1 if the keys were customized
+func (k *Keymap) Preset() int {
+	for i := range KeymapPresets {
+		matches := true
+		for a, keys := range KeymapPresets[i].parse() {
+			if !sameKeys(k.keys[a], keys) {
+				matches = false
+				break
+			}
+		}
+
+		if matches {
+			return i
+		}
+	}
+
+	return -1
+}
+
+func sameKeys(a, b []Key) bool {
+	if len(a) != len(b) {
+		return false
+	}
+
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+
+	return true
+}
+
+// Read overrides the keys of the commands listed in the keymap file, one "command = key key ..." per line
+func (k *Keymap) Read(r io.Reader) error {
+	scanner := bufio.NewScanner(r)
+	for line := 1; scanner.Scan(); line++ {
+		text := strings.TrimSpace(scanner.Text())
+		if text == "" || strings.HasPrefix(text, "#") {
+			continue
+		}
+
+		separator := strings.Index(text, "=")
+		if separator == -1 {
+			return fmt.Errorf("line %d: expected command = keys", line)
+		}
+
+		name := strings.TrimSpace(text[:separator])
+		a := Command(0)
+		for a < commandCount && commandInfos[a].name != name {
+			a++
+		}
+		if a == commandCount {
+			return fmt.Errorf("line %d: unknown command %q", line, name)
+		}
+
+		var keys []Key
+		for _, field := range strings.Fields(text[separator+1:]) {
+			key, err := ParseKey(field)
+			if err != nil {
+				return fmt.Errorf("line %d: %v", line, err)
+			}
+			keys = append(keys, key)
+		}
+
+		k.keys[a] = keys
+	}
+
+	if err := scanner.Err(); err != nil {
+		return err
+	}
+
+	return k.Conflicts()
+}
+
+// Write writes the keys of every Command in the format of the keymap file
+func (k *Keymap) Write(w io.Writer) error {
+	for a, keys := range k.keys {
+		names := make([]string, len(keys))
+		for i, key := range keys {
+			names[i] = key.String()
+		}
+
+		if _, err := fmt.Fprintf(w, "%s = %s\n", Command(a), strings.Join(names, " ")); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// LoadKeymap overrides the default keys with the keymap file, a missing file keeps the defaults
+func (k *Keymap) LoadKeymap(path string) error {
+	f, err := os.Open(path)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	if err := k.Read(f); err != nil {
+		return fmt.Errorf("%s: %v", path, err)
+	}
+
+	return nil
+}
+
+// SaveKeymap writes the keymap file, creating its directory if needed
+func (k *Keymap) SaveKeymap(path string) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+
+	if err := k.Write(f); err != nil {
+		f.Close()
+		return err
+	}
+
+	return f.Close()
+}
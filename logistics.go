@@ -0,0 +1,329 @@
+package main
+
+import (
+	"math"
+)
+
+const (
+	// LogisticRange the distance covered by a LogisticHub
+	LogisticRange float64 = 30
+	// LogisticHubBots the number of bots available in a LogisticHub
+	LogisticHubBots int = 4
+	// BotTicksPerTile the number of ticks a bot needs to fly over one tile
+	BotTicksPerTile int = 4
+	// CycleSizeLogistics the number of ticks between two dispatch rounds
+	CycleSizeLogistics int = 10
+)
+
+const (
+	// ChestKindPlain a Chest outside of the logistic network
+	ChestKindPlain int = iota
+	// ChestKindProvider a Chest that offers its Product-s to the logistic network
+	ChestKindProvider
+	// ChestKindRequester a Chest that asks the logistic network for Product-s
+	ChestKindRequester
+	// ChestKindStorage a Chest that offers its Product-s with a lower priority than providers
+	ChestKindStorage
+)
+
+// NewProviderChest creates a new *Chest that provides Product-s to the logistic network
+func NewProviderChest() *Chest {
+	return newChest(ChestKindProvider, "providerChest")
+}
+
+// NewRequesterChest creates a new *Chest that requests Product-s from the logistic network
+func NewRequesterChest() *Chest {
+	return newChest(ChestKindRequester, "requesterChest")
+}
+
+// NewStorageChest creates a new *Chest that stores Product-s for the logistic network
+func NewStorageChest() *Chest {
+	return newChest(ChestKindStorage, "storageChest")
+}
+
+// Requests returns the Product counts the Chest asks the logistic network for
+func (c *Chest) Requests() map[*Product]int {
+	return c.requests
+}
+
+// SetRequest specifies how many Product-s the requester Chest should hold
+func (c *Chest) SetRequest(p *Product, count int) {
+	if count <= 0 {
+		delete(c.requests, p)
+		return
+	}
+
+	c.requests[p] = count
+}
+
+// LogisticHub Structure that houses the bots of the logistic network
+type LogisticHub struct {
+	BaseStructure
+	bots int
+}
+
+// NewLogisticHub creates a new *LogisticHub
+func NewLogisticHub() *LogisticHub {
+	block := new(LogisticHub)
+	block.tiles = [][]StructureTile{
+		{NewFillerCornerTile(0), NewFillerCornerTile(1)},
+		{NewFillerCornerTile(3), NewFillerCornerTile(2)},
+	}
+
+	block.inputs = make([]Transfer, 0)
+	block.outputs = make([]Transfer, 0)
+	block.bots = LogisticHubBots
+
+	return block
+}
+
+// CopyStructure creates a copy of the LogisticHub
+func (h *LogisticHub) CopyStructure() Structure {
+	hub := new(LogisticHub)
+	hub.BaseStructure = *h.BaseStructure.copyStructure(hub)
+	hub.bots = h.bots
+
+	return hub
+}
+
+// Tick does nothing for LogisticHub, deliveries are handled by the Game
+func (h *LogisticHub) Tick() {
+}
+
+// CanRetrieveProduct does nothing for LogisticHub, it does not handle Product-s
+func (h *LogisticHub) CanRetrieveProduct() (*Product, bool) {
+	return nil, false
+}
+
+// RetrieveProduct does nothing for LogisticHub, it does not handle Product-s
+func (h *LogisticHub) RetrieveProduct() (*Product, bool) {
+	return nil, false
+}
+
+// CanAcceptProduct does nothing for LogisticHub, it does not handle Product-s
+func (h *LogisticHub) CanAcceptProduct(*Product) bool {
+	return false
+}
+
+// AcceptProduct does nothing for LogisticHub, it does not handle Product-s
+func (h *LogisticHub) AcceptProduct(*Product) bool {
+	return false
+}
+
+// RotateRight does nothing for a LogisticHub
+func (h *LogisticHub) RotateRight() {
+}
+
+// RotateLeft does nothing for a LogisticHub
+func (h *LogisticHub) RotateLeft() {
+}
+
+// GetCode return the code for the LogisticHub type
+func (*LogisticHub) GetCode() int {
+	return ProductStructureLogisticHub
+}
+
+// Delivery a Product carried by a bot from a LogisticHub, through the source Chest, to the target Chest
+type Delivery struct {
+	product *Product
+	hub     *LogisticHub
+	target  *Chest
+
+	hubPos    position
+	sourcePos position
+	targetPos position
+
+	elapsed int
+	total   int
+
+	tile *BaseStructureTile
+}
+
+func newDelivery(p *Product, hub *LogisticHub, target *Chest, hubPos, sourcePos, targetPos position) *Delivery {
+	d := new(Delivery)
+	d.product = p
+	d.hub = hub
+	d.target = target
+	d.hubPos = hubPos
+	d.sourcePos = sourcePos
+	d.targetPos = targetPos
+
+	length := distance(hubPos.x, hubPos.y, sourcePos.x, sourcePos.y) +
+		distance(sourcePos.x, sourcePos.y, targetPos.x, targetPos.y)
+	d.total = int(math.Ceil(length))*BotTicksPerTile + 1
+
+	d.tile = &BaseStructureTile{0, 1, "bot", nil, nil, nil}
+
+	return d
+}
+
+// Position returns the current position of the bot doing the Delivery
+func (d *Delivery) Position() position {
+	first := distance(d.hubPos.x, d.hubPos.y, d.sourcePos.x, d.sourcePos.y)
+	second := distance(d.sourcePos.x, d.sourcePos.y, d.targetPos.x, d.targetPos.y)
+
+	travelled := (first + second) * float64(d.elapsed) / float64(d.total)
+
+	from, to := d.hubPos, d.sourcePos
+	ratio := 1.
+	if travelled < first {
+		ratio = travelled / first
+	} else {
+		from, to = d.sourcePos, d.targetPos
+		if second > 0 {
+			ratio = (travelled - first) / second
+		}
+	}
+
+	x := float64(from.x) + float64(to.x-from.x)*ratio
+	y := float64(from.y) + float64(to.y-from.y)*ratio
+
+	return position{x: int(math.Round(x)), y: int(math.Round(y))}
+}
+
+// Tile returns the Tile used to display the bot doing the Delivery
+func (d *Delivery) Tile() Tile {
+	return d.tile
+}
+
+// Deliveries returns the active deliveries of the logistic network
+func (g *Game) Deliveries() []*Delivery {
+	return g.deliveries
+}
+
+// tickLogistics advances the active deliveries and dispatches new ones
+func (g *Game) tickLogistics() {
+	active := make([]*Delivery, 0, len(g.deliveries))
+	for _, d := range g.deliveries {
+		if d.elapsed < d.total {
+			d.elapsed++
+		}
+
+		if d.elapsed < d.total || !g.deliver(d) {
+			// the bot waits at the target until the Product can be delivered or returned
+			active = append(active, d)
+		}
+	}
+	g.deliveries = active
+
+	g.logisticsCounter++
+	if g.logisticsCounter < CycleSizeLogistics {
+		return
+	}
+	g.logisticsCounter = 0
+
+	for requester, requesterPos := range g.logisticChests {
+		if requester.kind != ChestKindRequester {
+			continue
+		}
+
+		for _, product := range GlobalProductFactory.cannonicalOrder {
+			requested, present := requester.requests[product]
+			if !present {
+				continue
+			}
+
+			missing := requested - requester.s.objects[product] - g.inFlight(requester, product)
+			if missing <= 0 {
+				continue
+			}
+
+			if g.dispatch(product, requester, requesterPos) {
+				// one dispatch per requester each round
+				break
+			}
+		}
+	}
+}
+
+// deliver hands the Product to the target Chest, or to the player when the target is gone or full
+func (g *Game) deliver(d *Delivery) bool {
+	if _, exists := g.logisticChests[d.target]; exists && d.target.AcceptProduct(d.product) {
+		return true
+	}
+
+	return g.inventory.Add(d.product, 1) == 1
+}
+
+func (g *Game) inFlight(target *Chest, p *Product) int {
+	count := 0
+	for _, d := range g.deliveries {
+		if d.target == target && d.product == p {
+			count++
+		}
+	}
+
+	return count
+}
+
+func (g *Game) busyBots(hub *LogisticHub) int {
+	count := 0
+	for _, d := range g.deliveries {
+		if d.hub == hub {
+			count++
+		}
+	}
+
+	return count
+}
+
+// dispatch sends a bot carrying the Product to the requester Chest, providers are used before storage
+func (g *Game) dispatch(p *Product, requester *Chest, requesterPos position) bool {
+	for _, kind := range []int{ChestKindProvider, ChestKindStorage} {
+		var source *Chest
+		var sourcePos position
+		best := math.MaxFloat64
+
+		for chest, pos := range g.logisticChests {
+			if chest.kind != kind || chest == requester {
+				continue
+			}
+
+			if count := chest.s.objects[p]; count <= 0 {
+				continue
+			}
+
+			d := distance(pos.x, pos.y, requesterPos.x, requesterPos.y)
+			if d < best {
+				best = d
+				source, sourcePos = chest, pos
+			}
+		}
+
+		if source == nil {
+			continue
+		}
+
+		var hub *LogisticHub
+		var hubPos position
+		best = math.MaxFloat64
+
+		for h, pos := range g.hubs {
+			if g.busyBots(h) >= h.bots {
+				continue
+			}
+
+			toSource := distance(pos.x, pos.y, sourcePos.x, sourcePos.y)
+			toTarget := distance(pos.x, pos.y, requesterPos.x, requesterPos.y)
+			if toSource > LogisticRange || toTarget > LogisticRange {
+				continue
+			}
+
+			if toSource < best {
+				best = toSource
+				hub, hubPos = h, pos
+			}
+		}
+
+		if hub == nil {
+			continue
+		}
+
+		source.s.Remove(p, 1)
+		g.deliveries = append(g.deliveries, newDelivery(p, hub, requester, hubPos, sourcePos, requesterPos))
+
+		return true
+	}
+
+	return false
+}
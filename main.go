@@ -2,6 +2,7 @@ package main
 
 import (
 	"log"
+	"os"
 	"time"
 
 	"github.com/jroimartin/gocui"
@@ -40,7 +41,8 @@ func newGameWindowManager(g *gocui.Gui) (*GameWindowManager, *GameWindow) {
 
 	gameWindow := NewGameWindow(&m)
 	settingsWindow := NewSettingsWindow(&m)
-	mainMenuWindow := NewPrimaryMenuWindow(&m, gameWindow, settingsWindow)
+	calculatorWindow := NewCalculatorWindow(&m)
+	mainMenuWindow := NewPrimaryMenuWindow(&m, gameWindow, settingsWindow, calculatorWindow)
 
 	m.SetTopWindow(mainMenuWindow)
 	g.SetManagerFunc(m.Layout)
@@ -59,6 +61,20 @@ func newGameWindowManager(g *gocui.Gui) (*GameWindowManager, *GameWindow) {
 }
 
 func main() {
+	if len(os.Args) > 1 {
+		if err := runCommand(os.Args[1:], os.Stdout); err != nil {
+			log.Fatalln(err)
+		}
+		return
+	}
+
+	// without a configuration directory the default settings are used
+	if dir, err := ConfigDir(); err == nil {
+		GlobalSettings.Load(dir)
+	}
+
+	GlobalDisplayConfigManager.ColorMode = DetectColorMode(os.Getenv("COLORTERM"), os.Getenv("TERM"))
+
 	g, err := gocui.NewGui(gocui.Output256)
 
 	if err != nil {
@@ -66,6 +82,9 @@ func main() {
 	}
 	defer g.Close()
 
+	// the input mode is chosen when the main loop starts
+	g.Mouse = true
+
 	m, gw := newGameWindowManager(g)
 
 	go uiLoop(m, g)
@@ -87,7 +106,7 @@ func uiLoop(m *GameWindowManager, g *gocui.Gui) {
 }
 
 func logicLoop(w *GameWindow) {
-	ticker := time.NewTicker(time.Millisecond * 10)
+	ticker := time.NewTicker(TickDuration)
 	defer ticker.Stop()
 
 	for {
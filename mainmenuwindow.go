@@ -2,6 +2,7 @@ package main
 
 import (
 	"fmt"
+	"strings"
 
 	"github.com/jroimartin/gocui"
 )
@@ -14,13 +15,13 @@ type PrimaryMenuWindow struct {
 }
 
 // NewPrimaryMenuWindow creates a new MainMenuWindow
-func NewPrimaryMenuWindow(manager WindowManager, gw *GameWindow, sw *SettingsWindow) *PrimaryMenuWindow {
+func NewPrimaryMenuWindow(manager WindowManager, gw *GameWindow, sw *SettingsWindow, cw *CalculatorWindow) *PrimaryMenuWindow {
 	var w PrimaryMenuWindow
 	w.manager = manager
 
 	w.widgets = append(w.widgets, newMascotWidget("Mascot", 1, 1))
 	w.widgets = append(w.widgets, newConveyorBeltWidget("ConveyorBelt", 24, 19))
-	w.widgets = append(w.widgets, newPrimaryMenuWidget("MainMenu", 24, 14, manager, gw, sw))
+	w.widgets = append(w.widgets, newPrimaryMenuWidget("MainMenu", 24, 11, &w, manager, gw, sw, cw))
 
 	return &w
 }
@@ -167,23 +168,35 @@ func (w *ConveyorBeltWidget) Layout(g *gocui.Gui) error {
 	return nil
 }
 
+// menuEntry returns the label of a menu entry with the key of its Command, highlighted as the first letter if they match
+func menuEntry(label string, c Command) string {
+	key := GlobalKeymap.Help(c)
+	if strings.EqualFold(key, label[:1]) {
+		return fmt.Sprintf("[%s]%s", strings.ToUpper(key), label[1:])
+	}
+
+	return fmt.Sprintf("%s [%s]", label, key)
+}
+
 // PrimaryMenuWidget a Widget that display the main menu
 type PrimaryMenuWidget struct {
-	name           string
-	x, y           int
-	selection      int
-	manager        WindowManager
-	gameWindow     *GameWindow
-	settingsWindow *SettingsWindow
+	name             string
+	x, y             int
+	selection        int
+	window           Window
+	manager          WindowManager
+	gameWindow       *GameWindow
+	settingsWindow   *SettingsWindow
+	calculatorWindow *CalculatorWindow
 }
 
-func newPrimaryMenuWidget(name string, x, y int, manager WindowManager, gameWindow *GameWindow, settingsWindow *SettingsWindow) *PrimaryMenuWidget {
-	return &PrimaryMenuWidget{name: name, x: x, y: y, selection: 0, gameWindow: gameWindow, manager: manager, settingsWindow: settingsWindow}
+func newPrimaryMenuWidget(name string, x, y int, window Window, manager WindowManager, gameWindow *GameWindow, settingsWindow *SettingsWindow, calculatorWindow *CalculatorWindow) *PrimaryMenuWidget {
+	return &PrimaryMenuWidget{name: name, x: x, y: y, selection: 0, window: window, gameWindow: gameWindow, manager: manager, settingsWindow: settingsWindow, calculatorWindow: calculatorWindow}
 }
 
 // Layout displays the PrimaryMenuWidget
 func (w *PrimaryMenuWidget) Layout(g *gocui.Gui) error {
-	v, err := g.SetView(w.name, w.x, w.y, w.x+16, w.y+5)
+	v, err := g.SetView(w.name, w.x, w.y, w.x+20, w.y+8)
 	if err == nil || err == gocui.ErrUnknownView {
 		if _, err := g.SetCurrentView(w.name); err != nil {
 			return err
@@ -196,9 +209,9 @@ func (w *PrimaryMenuWidget) Layout(g *gocui.Gui) error {
 		if err == gocui.ErrUnknownView {
 			v.Frame = false
 
-			if err := g.SetKeybinding(w.name, 'n', gocui.ModNone,
+			if err := GlobalKeymap.SetKeybinding(g, w.name, CommandNewGame,
 				func(g *gocui.Gui, v *gocui.View) error {
-					game := GenerateGame(120, 100)
+					game := GenerateGame(GlobalSettings.MapHeight, GlobalSettings.MapWidth)
 					w.gameWindow.SetGame(game)
 					w.manager.SetTopWindow(w.gameWindow)
 					w.gameWindow.SetRunning(true)
@@ -207,7 +220,18 @@ func (w *PrimaryMenuWidget) Layout(g *gocui.Gui) error {
 				}); err != nil {
 				return err
 			}
-			if err := g.SetKeybinding(w.name, 'c', gocui.ModNone,
+			if err := GlobalKeymap.SetKeybinding(g, w.name, CommandTutorial,
+				func(g *gocui.Gui, v *gocui.View) error {
+					w.gameWindow.SetGame(NewTutorialGame())
+					w.gameWindow.SetTutorial(NewTutorial())
+					w.manager.SetTopWindow(w.gameWindow)
+					w.gameWindow.SetRunning(true)
+
+					return nil
+				}); err != nil {
+				return err
+			}
+			if err := GlobalKeymap.SetKeybinding(g, w.name, CommandContinue,
 				func(g *gocui.Gui, v *gocui.View) error {
 					if w.gameWindow.HasGame() {
 						w.manager.SetTopWindow(w.gameWindow)
@@ -217,7 +241,7 @@ func (w *PrimaryMenuWidget) Layout(g *gocui.Gui) error {
 				}); err != nil {
 				return err
 			}
-			if err := g.SetKeybinding(w.name, 's', gocui.ModNone,
+			if err := GlobalKeymap.SetKeybinding(g, w.name, CommandSettings,
 				func(g *gocui.Gui, v *gocui.View) error {
 					w.manager.SetTopWindow(w.settingsWindow)
 
@@ -225,7 +249,23 @@ func (w *PrimaryMenuWidget) Layout(g *gocui.Gui) error {
 				}); err != nil {
 				return err
 			}
-			if err := g.SetKeybinding(w.name, 'q', gocui.ModNone,
+			if err := GlobalKeymap.SetKeybinding(g, w.name, CommandCalculator,
+				func(g *gocui.Gui, v *gocui.View) error {
+					w.manager.SetTopWindow(w.calculatorWindow)
+
+					return nil
+				}); err != nil {
+				return err
+			}
+			if err := GlobalKeymap.SetKeybinding(g, w.name, CommandHelp,
+				func(g *gocui.Gui, v *gocui.View) error {
+					w.gameWindow.help.Show(w.window)
+
+					return nil
+				}); err != nil {
+				return err
+			}
+			if err := GlobalKeymap.SetKeybinding(g, w.name, CommandQuit,
 				func(g *gocui.Gui, v *gocui.View) error {
 					return gocui.ErrQuit
 				}); err != nil {
@@ -235,12 +275,15 @@ func (w *PrimaryMenuWidget) Layout(g *gocui.Gui) error {
 
 		v.Clear()
 
-		fmt.Fprintf(v, "[N]ew game\n")
+		fmt.Fprintln(v, menuEntry("New game", CommandNewGame))
 		if w.gameWindow.HasGame() {
-			fmt.Fprintf(v, "[C]ontinue game\n")
+			fmt.Fprintln(v, menuEntry("Continue game", CommandContinue))
 		}
-		fmt.Fprintf(v, "[S]ettings\n")
-		fmt.Fprintf(v, "[Q]uit\n")
+		fmt.Fprintln(v, menuEntry("Tutorial", CommandTutorial))
+		fmt.Fprintln(v, menuEntry("Settings", CommandSettings))
+		fmt.Fprintln(v, menuEntry("Ratio calculator", CommandCalculator))
+		fmt.Fprintln(v, menuEntry("Help", CommandHelp))
+		fmt.Fprintln(v, menuEntry("Quit", CommandQuit))
 	}
 
 	return nil
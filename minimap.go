@@ -0,0 +1,129 @@
+package main
+
+import (
+	"fmt"
+)
+
+const (
+	// OverviewQuadrantTiles the width and height in tiles of one quadrant in the zoomed-out map
+	OverviewQuadrantTiles int = 2
+	// OverviewCellTiles the width and height in tiles of one character in the zoomed-out map
+	OverviewCellTiles int = 2 * OverviewQuadrantTiles
+	// MouseWheelRows the number of map rows scrolled by one step of the mouse wheel
+	MouseWheelRows int = 3
+)
+
+const (
+	mapKindEmpty int = iota
+	mapKindWater
+	mapKindOre
+	mapKindStructure
+)
+
+// mapKindColors the colors of the downsampled map, by the most important content of an area
+var mapKindColors = []int{37, 34, 33, 36}
+
+// brailleDots the bit of each dot of a braille character, indexed by row and column
+var brailleDots = [4][2]rune{
+	{0x01, 0x08},
+	{0x02, 0x10},
+	{0x04, 0x20},
+	{0x40, 0x80},
+}
+
+// brailleBase the braille character without dots
+const brailleBase rune = 0x2800
+
+// quadrantGlyphs the quadrant characters, indexed by the bits top left 1, top right 2, bottom left 4, bottom right 8
+var quadrantGlyphs = []rune(" ▘▝▀▖▌▞▛▗▚▐▜▄▙▟█")
+
+// tileKind classifies the tile at the location for the downsampled map
+func (g *Game) tileKind(y, x int) int {
+	switch t := g.WorldMap[y][x].(type) {
+	case StructureTile:
+		return mapKindStructure
+	case *RawResource:
+		if t.resource == ResourceWater {
+			return mapKindWater
+		}
+		if t.amount > 0 && t.resource != -1 {
+			return mapKindOre
+		}
+	}
+
+	return mapKindEmpty
+}
+
+// areaKind returns the most important content of the rectangle, the parts outside the map are ignored
+func (g *Game) areaKind(y, x, height, width int) int {
+	kind := mapKindEmpty
+	for i := y; i < y+height; i++ {
+		for j := x; j < x+width; j++ {
+			if !g.WithinBounds(j, i) {
+				continue
+			}
+
+			if k := g.tileKind(i, j); k > kind {
+				kind = k
+			}
+		}
+	}
+
+	return kind
+}
+
+// MinimapScale returns the number of tiles covered by one braille dot for the map to fit the width in characters
+func (g *Game) MinimapScale(width int) int {
+	worldX := len(g.WorldMap[0])
+	dots := 2 * width
+
+	return (worldX + dots - 1) / dots
+}
+
+// minimapCell renders one character of the minimap, each dot covering scale x scale tiles
+func (g *Game) minimapCell(row, column, scale int) (rune, int) {
+	glyph := brailleBase
+	kind := mapKindEmpty
+	for i := 0; i < 4; i++ {
+		for j := 0; j < 2; j++ {
+			k := g.areaKind((4*row+i)*scale, (2*column+j)*scale, scale, scale)
+			if k != mapKindEmpty {
+				glyph |= brailleDots[i][j]
+			}
+			if k > kind {
+				kind = k
+			}
+		}
+	}
+
+	return glyph, kind
+}
+
+// overviewCell renders one character of the zoomed-out map, each quadrant covering OverviewQuadrantTiles tiles
+func (g *Game) overviewCell(row, column int) (rune, int) {
+	bits := 0
+	kind := mapKindEmpty
+	for i := 0; i < 2; i++ {
+		for j := 0; j < 2; j++ {
+			k := g.areaKind(row*OverviewCellTiles+i*OverviewQuadrantTiles, column*OverviewCellTiles+j*OverviewQuadrantTiles,
+				OverviewQuadrantTiles, OverviewQuadrantTiles)
+			if k != mapKindEmpty && k != mapKindWater {
+				bits |= 1 << uint(2*i+j)
+			}
+			if k > kind {
+				kind = k
+			}
+		}
+	}
+
+	if bits == 0 && kind == mapKindWater {
+		return quadrantGlyphs[len(quadrantGlyphs)-1], kind
+	}
+
+	return quadrantGlyphs[bits], kind
+}
+
+// displayMapCell returns the colored character, the color mode highlights the cursor and the viewport
+func displayMapCell(glyph rune, kind int, colorMode int) string {
+	return fmt.Sprintf("\033[%d;%dm%c\033[0m", mapKindColors[kind], colorMode, glyph)
+}
@@ -1,5 +1,9 @@
 package main
 
+import (
+	"fmt"
+)
+
 // GlobalProductFactory a global product factory that contains all the Products
 var GlobalProductFactory = newProductFactory()
 
@@ -23,6 +27,8 @@ const (
 	ProductProcessedPlate
 	// ProductProcessedGear gear
 	ProductProcessedGear
+	// ProductProcessedConcrete concrete
+	ProductProcessedConcrete
 
 	// ProductStructureExtractor extractor
 	ProductStructureExtractor
@@ -36,6 +42,33 @@ const (
 	ProductStructureFactory
 	// ProductStructureUnderground underground
 	ProductStructureUnderground
+	// ProductStructurePump offshore pump
+	ProductStructurePump
+	// ProductStructurePipe pipe
+	ProductStructurePipe
+	// ProductStructureTank tank
+	ProductStructureTank
+	// ProductStructureRail rail
+	ProductStructureRail
+	// ProductStructureStation train station
+	ProductStructureStation
+	// ProductStructureProviderChest provider chest
+	ProductStructureProviderChest
+	// ProductStructureRequesterChest requester chest
+	ProductStructureRequesterChest
+	// ProductStructureStorageChest storage chest
+	ProductStructureStorageChest
+	// ProductStructureLogisticHub logistic hub
+	ProductStructureLogisticHub
+	// ProductStructureArithmeticCombinator arithmetic combinator
+	ProductStructureArithmeticCombinator
+	// ProductStructureDeciderCombinator decider combinator
+	ProductStructureDeciderCombinator
+
+	// ProductLocomotive locomotive
+	ProductLocomotive
+	// ProductWagon cargo wagon
+	ProductWagon
 )
 
 // Product generated by one of the machines in the world
@@ -43,12 +76,30 @@ type Product struct {
 	name           string
 	representation rune
 	structure      Structure
+
+	// glyphs the glyph of the Product in each symbol set, the representation is used in the other sets
+	glyphs map[string]rune
+	color  Color
+}
+
+func newProduct(name string, representation rune, structure Structure) *Product {
+	return &Product{name, representation, structure, make(map[string]rune), Color{}}
+}
+
+// Glyph returns the glyph of the Product on the map in the symbol set
+func (p *Product) Glyph(symbolSet string) rune {
+	if glyph, ok := p.glyphs[symbolSet]; ok {
+		return glyph
+	}
+
+	return p.representation
 }
 
 // ProductFactory factory for generating all the possible Products
 type ProductFactory struct {
 	products        map[int]*Product
 	cannonicalOrder []*Product
+	index           map[*Product]int
 }
 
 // GetProduct returns the Product identified by the product id
@@ -56,8 +107,14 @@ func (pf *ProductFactory) GetProduct(id int) *Product {
 	return pf.products[id]
 }
 
+// Index returns the position of the Product in the canonical order
+func (pf *ProductFactory) Index(p *Product) int {
+	return pf.index[p]
+}
+
 func (pf *ProductFactory) addProduct(id int, p *Product) {
 	pf.products[id] = p
+	pf.index[p] = len(pf.cannonicalOrder)
 	pf.cannonicalOrder = append(pf.cannonicalOrder, p)
 }
 
@@ -65,31 +122,139 @@ func newProductFactory() *ProductFactory {
 	pf := new(ProductFactory)
 	pf.products = make(map[int]*Product)
 	pf.cannonicalOrder = make([]*Product, 0)
+	pf.index = make(map[*Product]int)
+
+	pf.addProduct(ProductResourceCopper, newProduct("copper", 'c', nil))
+	pf.addProduct(ProductResourceIron, newProduct("iron", 'i', nil))
+	pf.addProduct(ProductResourceStone, newProduct("stone", 's', nil))
+
+	pf.addProduct(ProductProcessedCopperWire, newProduct("wire", 'w', nil))
+	pf.addProduct(ProductProcessedCircuitBoard, newProduct("circuit", 'C', nil))
+
+	pf.addProduct(ProductProcessedPlate, newProduct("plate", 'p', nil))
+	pf.addProduct(ProductProcessedGear, newProduct("gear", 'g', nil))
+	pf.addProduct(ProductProcessedConcrete, newProduct("concrete", 'k', nil))
+
+	pf.addProduct(ProductStructureExtractor, newProduct("extractor", 'e', NewExtractor()))
+	pf.addProduct(ProductStructureChest, newProduct("chest", 'S', NewChest()))
+	pf.addProduct(ProductStructureBelt, newProduct("belt", 'b', NewBelt()))
+	pf.addProduct(ProductStructureSplitter, newProduct("splitter", 'Y', NewSplitter()))
+	pf.addProduct(ProductStructureFactory, newProduct("factory", 'f', NewFactory()))
+	pf.addProduct(ProductStructureUnderground, newProduct("underground", 'u', NewUnderground()))
+	pf.addProduct(ProductStructurePump, newProduct("pump", 'P', NewOffshorePump()))
+	pf.addProduct(ProductStructurePipe, newProduct("pipe", '|', NewPipe()))
+	pf.addProduct(ProductStructureTank, newProduct("tank", 'T', NewTank()))
+	pf.addProduct(ProductStructureRail, newProduct("rail", 'r', NewRail()))
+	pf.addProduct(ProductStructureStation, newProduct("station", 'R', NewStation()))
+	pf.addProduct(ProductStructureProviderChest, newProduct("provider", 'v', NewProviderChest()))
+	pf.addProduct(ProductStructureRequesterChest, newProduct("requester", 'q', NewRequesterChest()))
+	pf.addProduct(ProductStructureStorageChest, newProduct("storage", 'o', NewStorageChest()))
+	pf.addProduct(ProductStructureLogisticHub, newProduct("hub", 'H', NewLogisticHub()))
+	pf.addProduct(ProductStructureArithmeticCombinator, newProduct("arithmetic", 'A', NewArithmeticCombinator()))
+	pf.addProduct(ProductStructureDeciderCombinator, newProduct("decider", 'D', NewDeciderCombinator()))
+
+	pf.addProduct(ProductLocomotive, newProduct("locomotive", 'L', nil))
+	pf.addProduct(ProductWagon, newProduct("wagon", 'W', nil))
+
+	// the structures keep their letter with distinct hues, the materials get shapes and colors of their own
+	for i, p := range pf.cannonicalOrder {
+		p.color = hueColor(i)
+	}
+
+	materials := []struct {
+		id    int
+		glyph rune
+		color Color
+	}{
+		{ProductResourceCopper, '\u25CF', RGBColor(0xd7, 0x87, 0x5f)},
+		{ProductResourceIron, '\u25A0', RGBColor(0xaf, 0xd7, 0xff)},
+		{ProductResourceStone, '\u25B2', RGBColor(0xbc, 0xbc, 0xbc)},
+		{ProductProcessedCopperWire, '\u223F', RGBColor(0xff, 0xaf, 0x00)},
+		{ProductProcessedCircuitBoard, '\u2317', RGBColor(0x5f, 0xd7, 0x5f)},
+		{ProductProcessedPlate, '\u25AC', RGBColor(0x87, 0xaf, 0xd7)},
+		{ProductProcessedGear, '\u2731', RGBColor(0xd7, 0xd7, 0x87)},
+		{ProductProcessedConcrete, '\u25A6', RGBColor(0xa8, 0xa8, 0xa8)},
+	}
+	for _, m := range materials {
+		p := pf.products[m.id]
+		p.glyphs[SymbolSetFreeMono] = m.glyph
+		p.color = m.color
+	}
+
+	for _, symbolSet := range []string{SymbolSetFreeMono, SymbolSetASCII} {
+		if err := pf.CheckGlyphs(symbolSet); err != nil {
+			panic(err)
+		}
+	}
+
+	return pf
+}
+
+// ProductByName returns the Product with the name, nil if there is none
+func (pf *ProductFactory) ProductByName(name string) *Product {
+	for _, p := range pf.cannonicalOrder {
+		if p.name == name {
+			return p
+		}
+	}
 
-	pf.addProduct(ProductResourceCopper, &Product{"copper", 'c', nil})
-	pf.addProduct(ProductResourceIron, &Product{"iron", 'i', nil})
-	pf.addProduct(ProductResourceStone, &Product{"stone", 's', nil})
+	return nil
+}
 
-	pf.addProduct(ProductProcessedCopperWire, &Product{"wire", 'w', nil})
-	pf.addProduct(ProductProcessedCircuitBoard, &Product{"circuit", 'C', nil})
+// Colors returns the colors of the Product-s in the canonical order
+func (pf *ProductFactory) Colors() []Color {
+	colors := make([]Color, len(pf.cannonicalOrder))
+	for i, p := range pf.cannonicalOrder {
+		colors[i] = p.color
+	}
 
-	pf.addProduct(ProductProcessedPlate, &Product{"plate", 'p', nil})
-	pf.addProduct(ProductProcessedGear, &Product{"gear", 'g', nil})
+	return colors
+}
 
-	pf.addProduct(ProductStructureExtractor, &Product{"extractor", 'e', NewExtractor()})
-	pf.addProduct(ProductStructureChest, &Product{"chest", 'S', NewChest()})
-	pf.addProduct(ProductStructureBelt, &Product{"belt", 'b', NewBelt()})
-	pf.addProduct(ProductStructureSplitter, &Product{"splitter", 's', NewSplitter()})
-	pf.addProduct(ProductStructureFactory, &Product{"factory", 'f', NewFactory()})
-	pf.addProduct(ProductStructureUnderground, &Product{"underground", 'u', NewUnderground()})
+// CheckGlyphs returns an error if two Product-s share a glyph in the symbol set, they could not be told apart on
+// the belts
+func (pf *ProductFactory) CheckGlyphs(symbolSet string) error {
+	seen := make(map[rune]*Product)
+	for _, p := range pf.cannonicalOrder {
+		glyph := p.Glyph(symbolSet)
+		if other, ok := seen[glyph]; ok {
+			return fmt.Errorf("%s and %s share the glyph %c in %s", other.name, p.name, glyph, symbolSet)
+		}
+		seen[glyph] = p
+	}
+
+	return nil
+}
 
-	return pf
+// SetGlyphs changes the glyphs of Product-s in the symbol set, nothing changes if the glyphs are not unique
+func (pf *ProductFactory) SetGlyphs(symbolSet string, glyphs map[*Product]rune) error {
+	previous := make(map[*Product]rune)
+	for p, glyph := range glyphs {
+		if old, ok := p.glyphs[symbolSet]; ok {
+			previous[p] = old
+		}
+		p.glyphs[symbolSet] = glyph
+	}
+
+	err := pf.CheckGlyphs(symbolSet)
+	if err != nil {
+		for p := range glyphs {
+			delete(p.glyphs, symbolSet)
+			if old, ok := previous[p]; ok {
+				p.glyphs[symbolSet] = old
+			}
+		}
+	}
+
+	return err
 }
 
 // Recipe indicates the production process required for creating a new Product
 type Recipe struct {
 	input           map[*Product]int
 	inputOrder      []*Product
+	fluidInput      map[*Fluid]int
+	fluidOrder      []*Fluid
 	output          *Product
 	productionTicks int
 }
@@ -98,6 +263,8 @@ func newRecipe(p *Product, ticks int) *Recipe {
 	recipe := new(Recipe)
 	recipe.input = make(map[*Product]int)
 	recipe.inputOrder = make([]*Product, 0)
+	recipe.fluidInput = make(map[*Fluid]int)
+	recipe.fluidOrder = make([]*Fluid, 0)
 	recipe.output = p
 	recipe.productionTicks = ticks
 
@@ -109,6 +276,11 @@ func (r *Recipe) addInput(p *Product, c int) {
 	r.inputOrder = append(r.inputOrder, p)
 }
 
+func (r *Recipe) addFluidInput(f *Fluid, c int) {
+	r.fluidInput[f] = c
+	r.fluidOrder = append(r.fluidOrder, f)
+}
+
 // RecipeFactory stores possible Recipes
 type RecipeFactory struct {
 	Assembly []*Recipe
@@ -141,6 +313,12 @@ func newRecipeFactory() *RecipeFactory {
 	rp.Assembly = append(rp.Assembly, recipe)
 
 	pStone := GlobalProductFactory.GetProduct(ProductResourceStone)
+	pConcrete := GlobalProductFactory.GetProduct(ProductProcessedConcrete)
+	recipe = newRecipe(pConcrete, 150)
+	recipe.addInput(pStone, 4)
+	recipe.addFluidInput(GlobalFluidFactory.GetFluid(FluidWater), 50)
+	rp.Assembly = append(rp.Assembly, recipe)
+
 	pExtractor := GlobalProductFactory.GetProduct(ProductStructureExtractor)
 	recipe = newRecipe(pExtractor, 200)
 	recipe.addInput(pStone, 10)
@@ -176,5 +354,67 @@ func newRecipeFactory() *RecipeFactory {
 	recipe.addInput(pGear, 5)
 	rp.Assembly = append(rp.Assembly, recipe)
 
+	pPump := GlobalProductFactory.GetProduct(ProductStructurePump)
+	recipe = newRecipe(pPump, 100)
+	recipe.addInput(pPlate, 5)
+	recipe.addInput(pGear, 2)
+	rp.Assembly = append(rp.Assembly, recipe)
+
+	pPipe := GlobalProductFactory.GetProduct(ProductStructurePipe)
+	recipe = newRecipe(pPipe, 20)
+	recipe.addInput(pPlate, 1)
+	rp.Assembly = append(rp.Assembly, recipe)
+
+	pTank := GlobalProductFactory.GetProduct(ProductStructureTank)
+	recipe = newRecipe(pTank, 150)
+	recipe.addInput(pPlate, 10)
+	recipe.addInput(pConcrete, 2)
+	rp.Assembly = append(rp.Assembly, recipe)
+
+	pRail := GlobalProductFactory.GetProduct(ProductStructureRail)
+	recipe = newRecipe(pRail, 40)
+	recipe.addInput(pStone, 1)
+	recipe.addInput(pPlate, 1)
+	rp.Assembly = append(rp.Assembly, recipe)
+
+	pStation := GlobalProductFactory.GetProduct(ProductStructureStation)
+	recipe = newRecipe(pStation, 150)
+	recipe.addInput(pPlate, 5)
+	recipe.addInput(pBoard, 2)
+	rp.Assembly = append(rp.Assembly, recipe)
+
+	for _, id := range []int{ProductStructureProviderChest, ProductStructureRequesterChest, ProductStructureStorageChest} {
+		recipe = newRecipe(GlobalProductFactory.GetProduct(id), 120)
+		recipe.addInput(pPlate, 4)
+		recipe.addInput(pBoard, 1)
+		rp.Assembly = append(rp.Assembly, recipe)
+	}
+
+	pHub := GlobalProductFactory.GetProduct(ProductStructureLogisticHub)
+	recipe = newRecipe(pHub, 300)
+	recipe.addInput(pPlate, 10)
+	recipe.addInput(pGear, 5)
+	recipe.addInput(pBoard, 5)
+	rp.Assembly = append(rp.Assembly, recipe)
+
+	for _, id := range []int{ProductStructureArithmeticCombinator, ProductStructureDeciderCombinator} {
+		recipe = newRecipe(GlobalProductFactory.GetProduct(id), 100)
+		recipe.addInput(pWire, 5)
+		recipe.addInput(pBoard, 5)
+		rp.Assembly = append(rp.Assembly, recipe)
+	}
+
+	pLocomotive := GlobalProductFactory.GetProduct(ProductLocomotive)
+	recipe = newRecipe(pLocomotive, 300)
+	recipe.addInput(pPlate, 20)
+	recipe.addInput(pGear, 10)
+	rp.Assembly = append(rp.Assembly, recipe)
+
+	pWagon := GlobalProductFactory.GetProduct(ProductWagon)
+	recipe = newRecipe(pWagon, 200)
+	recipe.addInput(pPlate, 10)
+	recipe.addInput(pGear, 4)
+	rp.Assembly = append(rp.Assembly, recipe)
+
 	return rp
 }
@@ -0,0 +1,322 @@
+package main
+
+import (
+	"container/heap"
+)
+
+const (
+	// RouteCostBelt the cost of routing through one Belt
+	RouteCostBelt int = 1
+	// RouteCostUnderground the cost of routing through one Underground, higher than the belts it replaces
+	RouteCostUnderground int = 6
+	// undergroundLength the distance between the entry and the exit of an Underground
+	undergroundLength int = 3
+)
+
+// RouteStep one Structure to be placed for a belt route
+type RouteStep struct {
+	pos position
+	s   Structure
+}
+
+// step returns the position next to p in the Direction
+func step(p position, d Direction, count int) position {
+	switch d {
+	case DirectionDown:
+		p.y += count
+	case DirectionLeft:
+		p.x -= count
+	case DirectionUp:
+		p.y -= count
+	case DirectionRight:
+		p.x += count
+	}
+
+	return p
+}
+
+// routeState a position reached by a Product moving in a Direction, not yet covered by a Structure
+type routeState struct {
+	pos position
+	d   Direction
+}
+
+type routeNode struct {
+	state    routeState
+	cost     int
+	priority int
+	index    int
+}
+
+// routeQueue a priority queue of routeNode-s, implements heap.Interface
+type routeQueue []*routeNode
+
+func (q routeQueue) Len() int { return len(q) }
+
+func (q routeQueue) Less(i, j int) bool { return q[i].priority < q[j].priority }
+
+func (q routeQueue) Swap(i, j int) {
+	q[i], q[j] = q[j], q[i]
+	q[i].index = i
+	q[j].index = j
+}
+
+func (q *routeQueue) Push(x interface{}) {
+	node := x.(*routeNode)
+	node.index = len(*q)
+	*q = append(*q, node)
+}
+
+func (q *routeQueue) Pop() interface{} {
+	old := *q
+	node := old[len(old)-1]
+	*q = old[:len(old)-1]
+
+	return node
+}
+
+// routeLink how a routeState was reached, used to rebuild the route
+type routeLink struct {
+	previous    routeState
+	underground bool
+}
+
+// FreeOutput returns the first output of the Structure at the location whose target tile is empty, in map coordinates
+func (g *Game) FreeOutput(y, x int) (Transfer, bool) {
+	s, sy, sx := g.GetStructureAt(y, x)
+	if s == nil {
+		return Transfer{}, false
+	}
+
+	for _, output := range s.Outputs() {
+		t := Transfer{sx + output.x, sy + output.y, output.d}
+		next := step(position{x: t.x, y: t.y}, t.d, 1)
+		if g.isRoutable(next) {
+			return t, true
+		}
+	}
+
+	return Transfer{}, false
+}
+
+// FreeInput returns the first input of the Structure at the location whose source tile is empty, in map coordinates
+func (g *Game) FreeInput(y, x int) (Transfer, bool) {
+	s, sy, sx := g.GetStructureAt(y, x)
+	if s == nil {
+		return Transfer{}, false
+	}
+
+	for _, input := range s.Inputs() {
+		t := Transfer{sx + input.x, sy + input.y, input.d}
+		previous := step(position{x: t.x, y: t.y}, (t.d+2)%4, 1)
+		if g.isRoutable(previous) {
+			return t, true
+		}
+	}
+
+	return Transfer{}, false
+}
+
+func (g *Game) isRoutable(p position) bool {
+	belt := GlobalProductFactory.GetProduct(ProductStructureBelt).structure
+	return g.CanPlaceStructure(p.y, p.x, belt)
+}
+
+// RouteBelts computes the Belt-s and Underground-s carrying Product-s from the output to the input, both
+// given in map coordinates, going around the existing Structure-s; it returns nil if there is no route
+func (g *Game) RouteBelts(from, to Transfer) []RouteStep {
+	start := routeState{step(position{x: from.x, y: from.y}, from.d, 1), from.d}
+	goal := step(position{x: to.x, y: to.y}, (to.d+2)%4, 1)
+
+	if !g.isRoutable(start.pos) || !g.isRoutable(goal) {
+		return nil
+	}
+
+	heuristic := func(p position) int {
+		dx, dy := p.x-goal.x, p.y-goal.y
+		if dx < 0 {
+			dx = -dx
+		}
+		if dy < 0 {
+			dy = -dy
+		}
+		return dx + dy
+	}
+
+	links := map[routeState]routeLink{start: {start, false}}
+	costs := map[routeState]int{start: 0}
+
+	queue := &routeQueue{}
+	heap.Push(queue, &routeNode{state: start, cost: 0, priority: heuristic(start.pos)})
+
+	var end routeState
+	var endUnderground, found bool
+
+	visit := func(next routeState, link routeLink, cost int) {
+		if previous, seen := costs[next]; seen && previous <= cost {
+			return
+		}
+
+		costs[next] = cost
+		links[next] = link
+		heap.Push(queue, &routeNode{state: next, cost: cost, priority: cost + heuristic(next.pos)})
+	}
+
+	for queue.Len() != 0 {
+		node := heap.Pop(queue).(*routeNode)
+		crt := node.state
+		if node.cost > costs[crt] {
+			continue
+		}
+
+		if crt.pos == goal && crt.d != (to.d+2)%4 {
+			end, found = crt, true
+			break
+		}
+
+		exit := step(crt.pos, crt.d, undergroundLength)
+		if exit == goal && crt.d == to.d && g.isRoutable(exit) {
+			// the Underground feeds the input directly
+			end, endUnderground, found = crt, true, true
+			break
+		}
+
+		for d := Direction(0); d < 4; d++ {
+			if d == (crt.d+2)%4 {
+				continue
+			}
+
+			next := step(crt.pos, d, 1)
+			if g.isRoutable(next) {
+				visit(routeState{next, d}, routeLink{crt, false}, node.cost+RouteCostBelt)
+			}
+		}
+
+		if g.isRoutable(exit) {
+			next := step(crt.pos, crt.d, undergroundLength+1)
+			if g.isRoutable(next) {
+				visit(routeState{next, crt.d}, routeLink{crt, true}, node.cost+RouteCostUnderground)
+			}
+		}
+	}
+
+	if !found {
+		return nil
+	}
+
+	// rebuild the route backwards, each state knows the Direction the Product leaves it
+	steps := make([]RouteStep, 0)
+	exitD := to.d
+	underground := endUnderground
+	for crt := end; ; {
+		if underground {
+			steps = append(steps, newUndergroundStep(crt.pos, crt.d))
+		} else {
+			steps = append(steps, RouteStep{crt.pos, newRoutedBelt(crt.d, exitD)})
+		}
+
+		if crt == start {
+			break
+		}
+
+		link := links[crt]
+		exitD = crt.d
+		crt, underground = link.previous, link.underground
+	}
+
+	for i, j := 0, len(steps)-1; i < j; i, j = i+1, j-1 {
+		steps[i], steps[j] = steps[j], steps[i]
+	}
+
+	// a route crossing itself cannot be built
+	used := make(map[position]bool)
+	for _, s := range steps {
+		for i, tiles := range s.s.Tiles() {
+			for j, tile := range tiles {
+				p := position{x: s.pos.x + j, y: s.pos.y + i}
+				if tile == nil {
+					continue
+				}
+				if used[p] {
+					return nil
+				}
+				used[p] = true
+			}
+		}
+	}
+
+	return steps
+}
+
+func newRoutedBelt(entry, exit Direction) Structure {
+	belt := GlobalProductFactory.GetProduct(ProductStructureBelt).structure.CopyStructure()
+	for r := beltRotation(entry, exit); r > 0; r-- {
+		belt.RotateRight()
+	}
+
+	return belt
+}
+
+// newUndergroundStep creates the Underground with the entry at the position, its top left corner
+// depends on the Direction
+func newUndergroundStep(entry position, d Direction) RouteStep {
+	underground := GlobalProductFactory.GetProduct(ProductStructureUnderground).structure.CopyStructure()
+	for r := d; r > 0; r-- {
+		underground.RotateRight()
+	}
+
+	exit := step(entry, d, undergroundLength)
+	corner := entry
+	if exit.x < corner.x {
+		corner.x = exit.x
+	}
+	if exit.y < corner.y {
+		corner.y = exit.y
+	}
+
+	return RouteStep{corner, underground}
+}
+
+// RouteRequirements returns the Product-s needed to build the route
+func RouteRequirements(steps []RouteStep) map[*Product]int {
+	requirements := make(map[*Product]int)
+	for _, s := range steps {
+		requirements[GlobalProductFactory.GetProduct(s.s.GetCode())]++
+	}
+
+	return requirements
+}
+
+// CanPlaceRoute indicates if the route can be placed and built from the inventory
+func (g *Game) CanPlaceRoute(steps []RouteStep) bool {
+	for product, count := range RouteRequirements(steps) {
+		if g.inventory.objects[product] < count {
+			return false
+		}
+	}
+
+	for _, s := range steps {
+		if !g.CanPlaceStructure(s.pos.y, s.pos.x, s.s) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// PlaceRoute builds the route from the inventory as a single Action
+func (g *Game) PlaceRoute(steps []RouteStep) bool {
+	if !g.CanPlaceRoute(steps) {
+		return false
+	}
+
+	actions := make(groupAction, 0, len(steps))
+	for _, s := range steps {
+		if a := g.buildStructure(s.pos.y, s.pos.x, s.s.CopyStructure()); a != nil {
+			actions = append(actions, a)
+		}
+	}
+	g.history.record(actions)
+
+	return true
+}
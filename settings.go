@@ -0,0 +1,217 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+const (
+	// settingsFile the name of the file storing the Settings
+	settingsFile = "settings"
+	// keymapFile the name of the file storing the keys of the GlobalKeymap
+	keymapFile = "keymap"
+
+	// AutosaveMax the longest autosave interval, in minutes
+	AutosaveMax int = 60
+	// MapSizeMin the smallest size of the map of a new game, in tiles
+	MapSizeMin int = 100
+	// MapSizeMax the largest size of the map of a new game, in tiles
+	MapSizeMax int = 400
+	// MapSizeStep the change of the size of the map in the settings
+	MapSizeStep int = 20
+)
+
+// GlobalSettings contains the preferences of the player
+var GlobalSettings = NewSettings()
+
+// Settings the preferences kept between games: the display configurations and cues, the keymap, the autosave
+// interval and the options of a new game
+type Settings struct {
+	dir string
+
+	// Problems the errors found while loading the settings, the defaults are kept for the faulty values
+	Problems []error
+
+	// Autosave the interval between saves of the running game in minutes, 0 disables it
+	Autosave  int
+	MapWidth  int
+	MapHeight int
+}
+
+// NewSettings creates new *Settings with the default values
+func NewSettings() *Settings {
+	s := new(Settings)
+	s.Autosave = 5
+	s.MapWidth = 100
+	s.MapHeight = 120
+
+	return s
+}
+
+// ConfigDir returns the directory of the game in the user configuration directory, $XDG_CONFIG_HOME on Linux
+func ConfigDir() (string, error) {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return "", err
+	}
+
+	return filepath.Join(dir, "GopherIndustries"), nil
+}
+
+// Read applies the settings file, one "name = value" per line; the lines that cannot be applied are added to the
+// Problems and skipped
+func (s *Settings) Read(r io.Reader) error {
+	m := GlobalDisplayConfigManager
+
+	scanner := bufio.NewScanner(r)
+	for line := 1; scanner.Scan(); line++ {
+		text := strings.TrimSpace(scanner.Text())
+		if text == "" || strings.HasPrefix(text, "#") {
+			continue
+		}
+
+		separator := strings.Index(text, "=")
+		if separator == -1 {
+			s.problem("line %d: expected name = value", line)
+			continue
+		}
+
+		name := strings.TrimSpace(text[:separator])
+		value := strings.TrimSpace(text[separator+1:])
+
+		switch name {
+		case "color":
+			index := m.ColorConfigIndex(value)
+			if index == -1 {
+				s.problem("line %d: unknown color configuration %q", line, value)
+				continue
+			}
+			m.SetColorConfig(index)
+		case "symbols":
+			index := m.SymbolConfigIndex(value)
+			if index == -1 {
+				s.problem("line %d: unknown symbol configuration %q", line, value)
+				continue
+			}
+			m.SetSymbolConfig(index)
+		case "cues":
+			if value != "on" && value != "off" {
+				s.problem("line %d: cues must be on or off", line)
+				continue
+			}
+			m.Cues = value == "on"
+		case "autosave":
+			n, err := strconv.Atoi(value)
+			if err != nil || n < 0 || n > AutosaveMax {
+				s.problem("line %d: autosave must be between 0 and %d minutes", line, AutosaveMax)
+				continue
+			}
+			s.Autosave = n
+		case "map-width", "map-height":
+			n, err := strconv.Atoi(value)
+			if err != nil || n < MapSizeMin || n > MapSizeMax {
+				s.problem("line %d: %s must be between %d and %d", line, name, MapSizeMin, MapSizeMax)
+				continue
+			}
+			if name == "map-width" {
+				s.MapWidth = n
+			} else {
+				s.MapHeight = n
+			}
+		default:
+			s.problem("line %d: unknown setting %q", line, name)
+		}
+	}
+
+	return scanner.Err()
+}
+
+// problem adds an error to the Problems
+func (s *Settings) problem(format string, a ...interface{}) {
+	s.Problems = append(s.Problems, fmt.Errorf(format, a...))
+}
+
+// Write writes the current settings in the format of the settings file
+func (s *Settings) Write(w io.Writer) error {
+	m := GlobalDisplayConfigManager
+
+	cues := "off"
+	if m.Cues {
+		cues = "on"
+	}
+
+	_, err := fmt.Fprintf(w, "color = %s\nsymbols = %s\ncues = %s\nautosave = %d\nmap-width = %d\nmap-height = %d\n",
+		m.GetColorConfig().Name, m.GetSymbolConfig().Name, cues, s.Autosave, s.MapWidth, s.MapHeight)
+
+	return err
+}
+
+// Load reads the themes, the settings file and the keymap file of the directory, missing files keep the defaults;
+// the errors are added to the Problems instead of stopping the game
+func (s *Settings) Load(dir string) {
+	s.dir = dir
+
+	// the settings choose the display configurations by name, themes included
+	s.Problems = append(s.Problems, GlobalDisplayConfigManager.LoadThemes(filepath.Join(dir, themesDir))...)
+
+	path := filepath.Join(dir, settingsFile)
+	f, err := os.Open(path)
+	if err != nil && !os.IsNotExist(err) {
+		s.Problems = append(s.Problems, err)
+	}
+	if err == nil {
+		defer f.Close()
+
+		// the problems of the lines are reported with the path of the file
+		problems := len(s.Problems)
+		err := s.Read(f)
+		for i := problems; i < len(s.Problems); i++ {
+			s.Problems[i] = fmt.Errorf("%s: %v", path, s.Problems[i])
+		}
+		if err != nil {
+			s.Problems = append(s.Problems, fmt.Errorf("%s: %v", path, err))
+		}
+	}
+
+	// a faulty keymap file is ignored as a whole, its keys may conflict
+	if err := GlobalKeymap.LoadKeymap(filepath.Join(dir, keymapFile)); err != nil {
+		s.Problems = append(s.Problems, err)
+		GlobalKeymap = NewKeymap()
+	}
+}
+
+// Save writes the settings file and the keymap file, creating their directory if needed
+func (s *Settings) Save() error {
+	if s.dir == "" {
+		dir, err := ConfigDir()
+		if err != nil {
+			return err
+		}
+		s.dir = dir
+	}
+
+	if err := os.MkdirAll(s.dir, 0755); err != nil {
+		return err
+	}
+
+	f, err := os.Create(filepath.Join(s.dir, settingsFile))
+	if err != nil {
+		return err
+	}
+
+	if err := s.Write(f); err != nil {
+		f.Close()
+		return err
+	}
+
+	if err := f.Close(); err != nil {
+		return err
+	}
+
+	return GlobalKeymap.SaveKeymap(filepath.Join(s.dir, keymapFile))
+}
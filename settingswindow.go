@@ -2,6 +2,7 @@ package main
 
 import (
 	"fmt"
+	"strings"
 
 	"github.com/jroimartin/gocui"
 )
@@ -10,6 +11,7 @@ import (
 type SettingsWindow struct {
 	manager WindowManager
 	widgets []Widget
+	err     error
 }
 
 // NewSettingsWindow creates a new SettingsWindow
@@ -17,17 +19,25 @@ func NewSettingsWindow(manager WindowManager) *SettingsWindow {
 	var w SettingsWindow
 	w.manager = manager
 
-	colorMenuWidget := &ColorMenuWidget{"ColorMenu", 0, nil, true}
-	symbolMenuWidget := &SymbolMenuWidget{"SymbolMenu", 0, nil, false}
+	m := GlobalDisplayConfigManager
+	colorMenuWidget := &ColorMenuWidget{"ColorMenu", m.ColorConfigIndex(m.GetColorConfig().Name), nil, true, &w}
+	symbolMenuWidget := &SymbolMenuWidget{"SymbolMenu", m.SymbolConfigIndex(m.GetSymbolConfig().Name), nil, false, &w}
+	optionsWidget := &OptionsWidget{name: "Options", window: &w}
 	sampleWidget := newSampleWidget()
+	keymapWidget := &KeymapWidget{name: "KeymapWidget"}
 
 	colorMenuWidget.other = symbolMenuWidget
-	symbolMenuWidget.other = sampleWidget
-	sampleWidget.other = colorMenuWidget
+	symbolMenuWidget.other = optionsWidget
+	optionsWidget.other = sampleWidget
+	sampleWidget.other = keymapWidget
+	keymapWidget.other = colorMenuWidget
 
 	w.widgets = append(w.widgets, colorMenuWidget)
 	w.widgets = append(w.widgets, symbolMenuWidget)
+	w.widgets = append(w.widgets, optionsWidget)
 	w.widgets = append(w.widgets, sampleWidget)
+	w.widgets = append(w.widgets, keymapWidget)
+	w.widgets = append(w.widgets, &ProblemsWidget{"ProblemsWidget"})
 
 	return &w
 }
@@ -44,7 +54,13 @@ func (w *SettingsWindow) Layout(g *gocui.Gui) error {
 			return err
 		}
 
-		v.Title = "Settings"
+		v.Title = fmt.Sprintf("Settings - %s terminal", GlobalDisplayConfigManager.ColorMode)
+		if w.err != nil {
+			v.Title += fmt.Sprintf(" - not saved: %v", w.err)
+		}
+		if n := len(GlobalSettings.Problems); n > 0 {
+			v.Title += fmt.Sprintf(" - %d problems loading the settings", n)
+		}
 	}
 
 	for _, widget := range w.widgets {
@@ -54,19 +70,57 @@ func (w *SettingsWindow) Layout(g *gocui.Gui) error {
 	return nil
 }
 
+// ProblemsWidget lists the problems found while loading the settings, below the other widgets when there is room
+type ProblemsWidget struct {
+	name string
+}
+
+// Layout displays the ProblemsWidget
+func (w *ProblemsWidget) Layout(g *gocui.Gui) error {
+	_, maxY := g.Size()
+	if len(GlobalSettings.Problems) == 0 || maxY-2 < 24 {
+		g.DeleteView(w.name)
+		return nil
+	}
+
+	v, err := g.SetView(w.name, 1, 21, 40, maxY-2)
+
+	if err == gocui.ErrUnknownView {
+		v.Title = "Problems"
+		v.Wrap = true
+	}
+
+	if err == nil || err == gocui.ErrUnknownView {
+		v.Clear()
+
+		if _, err := g.SetViewOnTop(w.name); err != nil {
+			return err
+		}
+
+		for _, problem := range GlobalSettings.Problems {
+			fmt.Fprintln(v, problem)
+		}
+	}
+
+	return nil
+}
+
 // ColorMenuWidget allows the selection of new color modes
 type ColorMenuWidget struct {
-	name  string
-	sel   int
-	other *SymbolMenuWidget
-	focus bool
+	name   string
+	sel    int
+	other  *SymbolMenuWidget
+	focus  bool
+	window *SettingsWindow
 }
 
 // Layout displays the ColorMenuWidget
 func (w *ColorMenuWidget) Layout(g *gocui.Gui) error {
-	v, err := g.SetView(w.name, 1, 1, 12, 4) //GlobalDisplayConfigManager
+	v, err := g.SetView(w.name, 1, 1, 19, 5)
 
 	if err == gocui.ErrUnknownView {
+		v.Title = "Colors"
+
 		if err := g.SetKeybinding(w.name, gocui.KeyArrowUp, gocui.ModNone,
 			func(g *gocui.Gui, v *gocui.View) error {
 				w.sel--
@@ -75,6 +129,7 @@ func (w *ColorMenuWidget) Layout(g *gocui.Gui) error {
 				}
 
 				GlobalDisplayConfigManager.SetColorConfig(w.sel)
+				w.window.err = GlobalSettings.Save()
 				return nil
 			}); err != nil {
 			return err
@@ -88,6 +143,7 @@ func (w *ColorMenuWidget) Layout(g *gocui.Gui) error {
 				}
 
 				GlobalDisplayConfigManager.SetColorConfig(w.sel)
+				w.window.err = GlobalSettings.Save()
 				return nil
 			}); err != nil {
 			return err
@@ -115,7 +171,18 @@ func (w *ColorMenuWidget) Layout(g *gocui.Gui) error {
 			return err
 		}
 
+		// the themes of the user can outgrow the view
+		_, height := v.Size()
+		start := 0
+		if w.sel >= height {
+			start = w.sel - height + 1
+		}
+
 		for i, colorConfig := range GlobalDisplayConfigManager.ColorConfigs {
+			if i < start {
+				continue
+			}
+
 			prefix := ' '
 			if w.sel == i {
 				prefix = '>'
@@ -137,17 +204,20 @@ func (w *ColorMenuWidget) Layout(g *gocui.Gui) error {
 
 // SymbolMenuWidget allows the selection of new symbol modes
 type SymbolMenuWidget struct {
-	name  string
-	sel   int
-	other *SampleWidget
-	focus bool
+	name   string
+	sel    int
+	other  *OptionsWidget
+	focus  bool
+	window *SettingsWindow
 }
 
 // Layout displays the SymbolMenuWidget
 func (w *SymbolMenuWidget) Layout(g *gocui.Gui) error {
-	v, err := g.SetView(w.name, 1, 5, 12, 8)
+	v, err := g.SetView(w.name, 1, 6, 19, 10)
 
 	if err == gocui.ErrUnknownView {
+		v.Title = "Symbols"
+
 		if err := g.SetKeybinding(w.name, gocui.KeyArrowUp, gocui.ModNone,
 			func(g *gocui.Gui, v *gocui.View) error {
 				w.sel--
@@ -156,6 +226,7 @@ func (w *SymbolMenuWidget) Layout(g *gocui.Gui) error {
 				}
 
 				GlobalDisplayConfigManager.SetSymbolConfig(w.sel)
+				w.window.err = GlobalSettings.Save()
 				return nil
 			}); err != nil {
 			return err
@@ -169,6 +240,7 @@ func (w *SymbolMenuWidget) Layout(g *gocui.Gui) error {
 				}
 
 				GlobalDisplayConfigManager.SetSymbolConfig(w.sel)
+				w.window.err = GlobalSettings.Save()
 				return nil
 			}); err != nil {
 			return err
@@ -196,7 +268,18 @@ func (w *SymbolMenuWidget) Layout(g *gocui.Gui) error {
 			return err
 		}
 
+		// the themes of the user can outgrow the view
+		_, height := v.Size()
+		start := 0
+		if w.sel >= height {
+			start = w.sel - height + 1
+		}
+
 		for i, colorConfig := range GlobalDisplayConfigManager.SymbolConfigs {
+			if i < start {
+				continue
+			}
+
 			prefix := ' '
 			if w.sel == i {
 				prefix = '>'
@@ -216,6 +299,138 @@ func (w *SymbolMenuWidget) Layout(g *gocui.Gui) error {
 	return nil
 }
 
+// OptionsWidget changes the cues, the autosave interval and the options of a new game
+type OptionsWidget struct {
+	name   string
+	sel    int
+	other  *SampleWidget
+	focus  bool
+	window *SettingsWindow
+}
+
+// change moves the selected option by a step, within its bounds
+func (w *OptionsWidget) change(direction int) {
+	s := GlobalSettings
+
+	switch w.sel {
+	case 0:
+		GlobalDisplayConfigManager.Cues = !GlobalDisplayConfigManager.Cues
+	case 1:
+		s.Autosave = clamp(s.Autosave+direction, 0, AutosaveMax)
+	case 2:
+		s.MapWidth = clamp(s.MapWidth+direction*MapSizeStep, MapSizeMin, MapSizeMax)
+	case 3:
+		s.MapHeight = clamp(s.MapHeight+direction*MapSizeStep, MapSizeMin, MapSizeMax)
+	}
+
+	w.window.err = s.Save()
+}
+
+// Layout displays the OptionsWidget
+func (w *OptionsWidget) Layout(g *gocui.Gui) error {
+	v, err := g.SetView(w.name, 1, 11, 19, 16)
+
+	if err == gocui.ErrUnknownView {
+		v.Title = "Options ←→"
+
+		if err := g.SetKeybinding(w.name, gocui.KeyArrowUp, gocui.ModNone,
+			func(g *gocui.Gui, v *gocui.View) error {
+				w.sel--
+				if w.sel < 0 {
+					w.sel = 0
+				}
+
+				return nil
+			}); err != nil {
+			return err
+		}
+		if err := g.SetKeybinding(w.name, gocui.KeyArrowDown, gocui.ModNone,
+			func(g *gocui.Gui, v *gocui.View) error {
+				w.sel++
+				if w.sel > 3 {
+					w.sel = 3
+				}
+
+				return nil
+			}); err != nil {
+			return err
+		}
+		if err := g.SetKeybinding(w.name, gocui.KeyArrowLeft, gocui.ModNone,
+			func(g *gocui.Gui, v *gocui.View) error {
+				w.change(-1)
+
+				return nil
+			}); err != nil {
+			return err
+		}
+		if err := g.SetKeybinding(w.name, gocui.KeyArrowRight, gocui.ModNone,
+			func(g *gocui.Gui, v *gocui.View) error {
+				w.change(1)
+
+				return nil
+			}); err != nil {
+			return err
+		}
+		if err := g.SetKeybinding(w.name, gocui.KeyTab, gocui.ModNone,
+			func(g *gocui.Gui, v *gocui.View) error {
+				w.focus = false
+				w.other.focus = true
+
+				return nil
+			}); err != nil {
+			return err
+		}
+	}
+
+	if err == nil || err == gocui.ErrUnknownView {
+		v.Clear()
+		if w.focus {
+			if _, err := g.SetCurrentView(w.name); err != nil {
+				return err
+			}
+		}
+
+		if _, err := g.SetViewOnTop(w.name); err != nil {
+			return err
+		}
+
+		autosave := "off"
+		if GlobalSettings.Autosave > 0 {
+			autosave = fmt.Sprintf("%dm", GlobalSettings.Autosave)
+		}
+
+		cues := "off"
+		if GlobalDisplayConfigManager.Cues {
+			cues = "on"
+		}
+
+		options := [][]string{
+			{"cues", cues},
+			{"autosave", autosave},
+			{"width", fmt.Sprint(GlobalSettings.MapWidth)},
+			{"height", fmt.Sprint(GlobalSettings.MapHeight)},
+		}
+
+		for i, option := range options {
+			prefix := ' '
+			if w.sel == i {
+				prefix = '>'
+			}
+
+			colorPrefix := ""
+			colorSuffix := ""
+			if w.focus {
+				colorPrefix = "\033[31;1m"
+				colorSuffix = "\033[0m"
+			}
+
+			fmt.Fprintf(v, "%s%c%s %-9s%s\n", colorPrefix, prefix, colorSuffix, option[0], option[1])
+		}
+	}
+
+	return nil
+}
+
 // SampleWidget sample display of the current color and symbol selections
 type SampleWidget struct {
 	name   string
@@ -225,7 +440,7 @@ type SampleWidget struct {
 	s      [][]Structure
 	sNames []string
 
-	other *ColorMenuWidget
+	other *KeymapWidget
 }
 
 func newSampleWidget() *SampleWidget {
@@ -404,3 +619,188 @@ func (w *SampleWidget) getTiles(structures []Structure) [][]Tile {
 
 	return responseTiles
 }
+
+// KeymapWidget lists the keys bound to each Command and allows rebinding them
+type KeymapWidget struct {
+	name      string
+	sel       int
+	focus     bool
+	capturing bool
+	captured  *Key
+	err       error
+
+	other *ColorMenuWidget
+}
+
+// Layout displays the KeymapWidget, while rebinding a Command the next key pressed is captured
+func (w *KeymapWidget) Layout(g *gocui.Gui) error {
+	maxX, maxY := g.Size()
+
+	v, err := g.SetView(w.name, 41, 1, maxX-2, maxY-2)
+
+	if err == gocui.ErrUnknownView {
+		if err := g.SetKeybinding(w.name, gocui.KeyArrowUp, gocui.ModNone,
+			func(g *gocui.Gui, v *gocui.View) error {
+				w.sel--
+				if w.sel < 0 {
+					w.sel = 0
+				}
+
+				return nil
+			}); err != nil {
+			return err
+		}
+		if err := g.SetKeybinding(w.name, gocui.KeyArrowDown, gocui.ModNone,
+			func(g *gocui.Gui, v *gocui.View) error {
+				w.sel++
+				if w.sel > int(commandCount)-1 {
+					w.sel = int(commandCount) - 1
+				}
+
+				return nil
+			}); err != nil {
+			return err
+		}
+		if err := g.SetKeybinding(w.name, gocui.KeyEnter, gocui.ModNone,
+			func(g *gocui.Gui, v *gocui.View) error {
+				w.capturing = true
+				w.err = nil
+
+				return nil
+			}); err != nil {
+			return err
+		}
+		if err := g.SetKeybinding(w.name, 'p', gocui.ModNone,
+			func(g *gocui.Gui, v *gocui.View) error {
+				// a customized keymap starts over from the first preset
+				preset := (GlobalKeymap.Preset() + 1) % len(KeymapPresets)
+
+				w.err = GlobalKeymap.ApplyPreset(g, &KeymapPresets[preset])
+				if w.err == nil {
+					w.err = GlobalSettings.Save()
+				}
+
+				return nil
+			}); err != nil {
+			return err
+		}
+		if err := g.SetKeybinding(w.name, gocui.KeyTab, gocui.ModNone,
+			func(g *gocui.Gui, v *gocui.View) error {
+				w.focus = false
+				w.other.focus = true
+
+				return nil
+			}); err != nil {
+			return err
+		}
+	}
+
+	if err != nil && err != gocui.ErrUnknownView {
+		return err
+	}
+
+	if w.captured != nil {
+		w.err = GlobalKeymap.Rebind(g, Command(w.sel), []Key{*w.captured})
+		if w.err == nil {
+			w.err = GlobalSettings.Save()
+		}
+		w.captured = nil
+	}
+
+	v.Clear()
+	v.Title = "Keys - custom"
+	if preset := GlobalKeymap.Preset(); preset != -1 {
+		v.Title = fmt.Sprintf("Keys - %s", KeymapPresets[preset].Name)
+	}
+
+	if _, err := g.SetViewOnTop(w.name); err != nil {
+		return err
+	}
+
+	if w.focus {
+		if _, err := g.SetCurrentView(w.name); err != nil {
+			return err
+		}
+	}
+
+	if w.err != nil {
+		fmt.Fprintf(v, "\033[31;1m%s\033[0m\n", w.err)
+	}
+
+	_, height := v.Size()
+	if w.err != nil {
+		height--
+	}
+
+	start := 0
+	if w.sel >= height {
+		start = w.sel - height + 1
+	}
+
+	for c := Command(start); c < commandCount; c++ {
+		prefix := ' '
+		if w.sel == int(c) {
+			prefix = '>'
+		}
+
+		colorPrefix := ""
+		colorSuffix := ""
+		if w.focus {
+			colorPrefix = "\033[31;1m"
+			colorSuffix = "\033[0m"
+		}
+
+		keys := GlobalKeymap.Keys(c)
+		names := make([]string, len(keys))
+		for i, key := range keys {
+			names[i] = key.String()
+		}
+
+		fmt.Fprintf(v, "%s%c%s %-12s %s\n", colorPrefix, prefix, colorSuffix, c, strings.Join(names, " "))
+	}
+
+	return w.layoutCapture(g)
+}
+
+// layoutCapture displays the prompt for the new key, the key is read by the Editor of the prompt
+func (w *KeymapWidget) layoutCapture(g *gocui.Gui) error {
+	captureName := w.name + "Capture"
+	if !w.capturing {
+		g.DeleteView(captureName)
+		return nil
+	}
+
+	maxX, _ := g.Size()
+
+	v, err := g.SetView(captureName, 43, 3, maxX-4, 5)
+	if err != nil && err != gocui.ErrUnknownView {
+		return err
+	}
+
+	if err == gocui.ErrUnknownView {
+		v.Title = "Press a key"
+		v.Editable = true
+		v.Editor = gocui.EditorFunc(func(v *gocui.View, key gocui.Key, ch rune, mod gocui.Modifier) {
+			if k, ok := newKey(key, ch); ok {
+				w.captured = &k
+			} else {
+				w.err = fmt.Errorf("key cannot be bound")
+			}
+
+			w.capturing = false
+		})
+	}
+
+	v.Clear()
+	fmt.Fprintf(v, "%s", Command(w.sel))
+
+	if _, err := g.SetViewOnTop(captureName); err != nil {
+		return err
+	}
+
+	if _, err := g.SetCurrentView(captureName); err != nil {
+		return err
+	}
+
+	return nil
+}
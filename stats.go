@@ -0,0 +1,163 @@
+package main
+
+const (
+	// StatsBuckets the number of samples kept for each statistics window
+	StatsBuckets int = 60
+)
+
+// StatsWindow a rolling window of game time over which Product-s are counted
+type StatsWindow struct {
+	name  string
+	ticks int
+}
+
+// statsWindows the windows tracked by ProductionStats: 1 minute, 10 minutes and 1 hour of game time
+var statsWindows = []StatsWindow{
+	{"1m", int(TicksPerMinute)},
+	{"10m", 10 * int(TicksPerMinute)},
+	{"1h", 60 * int(TicksPerMinute)},
+}
+
+// statsSeries the production and consumption samples of one StatsWindow, each sample covers
+// a fixed number of ticks and the samples are stored in a ring
+type statsSeries struct {
+	bucketTicks int
+	current     int
+	produced    map[*Product][]int
+	consumed    map[*Product][]int
+}
+
+func newStatsSeries(window StatsWindow) *statsSeries {
+	s := new(statsSeries)
+	s.bucketTicks = window.ticks / StatsBuckets
+	s.produced = make(map[*Product][]int)
+	s.consumed = make(map[*Product][]int)
+
+	return s
+}
+
+func (s *statsSeries) add(samples map[*Product][]int, p *Product, count int) {
+	buckets, ok := samples[p]
+	if !ok {
+		buckets = make([]int, StatsBuckets)
+		samples[p] = buckets
+	}
+
+	buckets[s.current] += count
+}
+
+func (s *statsSeries) advance() {
+	s.current = (s.current + 1) % StatsBuckets
+	for _, buckets := range s.produced {
+		buckets[s.current] = 0
+	}
+	for _, buckets := range s.consumed {
+		buckets[s.current] = 0
+	}
+}
+
+// ordered returns the samples of the Product from the oldest to the newest
+func (s *statsSeries) ordered(samples map[*Product][]int, p *Product) []int {
+	result := make([]int, StatsBuckets)
+
+	buckets, ok := samples[p]
+	if !ok {
+		return result
+	}
+
+	for i := range result {
+		result[i] = buckets[(s.current+1+i)%StatsBuckets]
+	}
+
+	return result
+}
+
+// ProductionStats counts the Product-s produced and consumed by the Game over rolling windows
+type ProductionStats struct {
+	tick   int
+	series []*statsSeries
+}
+
+// NewProductionStats creates a new *ProductionStats
+func NewProductionStats() *ProductionStats {
+	stats := new(ProductionStats)
+	stats.series = make([]*statsSeries, len(statsWindows))
+	for i, window := range statsWindows {
+		stats.series[i] = newStatsSeries(window)
+	}
+
+	return stats
+}
+
+// Tick advances the game time of the ProductionStats, dropping the samples leaving the windows
+func (stats *ProductionStats) Tick() {
+	stats.tick++
+	for _, s := range stats.series {
+		if stats.tick%s.bucketTicks == 0 {
+			s.advance()
+		}
+	}
+}
+
+// Produce records Product-s output by an Extractor or a Factory
+func (stats *ProductionStats) Produce(p *Product, count int) {
+	for _, s := range stats.series {
+		s.add(s.produced, p, count)
+	}
+}
+
+// Consume records Product-s used as input by a Factory
+func (stats *ProductionStats) Consume(p *Product, count int) {
+	for _, s := range stats.series {
+		s.add(s.consumed, p, count)
+	}
+}
+
+// Produced returns the Product-s produced during the window, from the oldest sample to the newest
+func (stats *ProductionStats) Produced(window int, p *Product) []int {
+	s := stats.series[window]
+	return s.ordered(s.produced, p)
+}
+
+// Consumed returns the Product-s consumed during the window, from the oldest sample to the newest
+func (stats *ProductionStats) Consumed(window int, p *Product) []int {
+	s := stats.series[window]
+	return s.ordered(s.consumed, p)
+}
+
+// tickStructure advances the Structure, recording the Product-s it produces and consumes
+func (g *Game) tickStructure(s Structure) {
+	switch ss := s.(type) {
+	case *Extractor:
+		hadProduct := ss.product != nil
+		ss.Tick()
+
+		if !hadProduct && ss.product != nil {
+			g.stats.Produce(ss.product, 1)
+		}
+	case *Factory:
+		counter := ss.counter
+		ss.Tick()
+
+		if ss.recipe == nil || counter == ss.counter {
+			return
+		}
+
+		if counter == 0 {
+			for p, c := range ss.recipe.input {
+				g.stats.Consume(p, c)
+			}
+		}
+
+		if ss.counter == ss.recipe.productionTicks {
+			g.stats.Produce(ss.recipe.output, 1)
+		}
+	default:
+		s.Tick()
+	}
+}
+
+// Stats returns the production statistics of the Game
+func (g *Game) Stats() *ProductionStats {
+	return g.stats
+}
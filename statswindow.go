@@ -0,0 +1,212 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/jroimartin/gocui"
+)
+
+// sparkBlocks the block characters used for the sparklines, from the lowest to the highest sample
+var sparkBlocks = []rune{' ', '▁', '▂', '▃', '▄', '▅', '▆', '▇', '█'}
+
+// sparkline renders the samples with block characters, scaled to the largest sample
+func sparkline(samples []int, width int) string {
+	if width < 0 {
+		width = 0
+	}
+	if width < len(samples) {
+		samples = samples[len(samples)-width:]
+	}
+
+	max := 0
+	for _, sample := range samples {
+		if sample > max {
+			max = sample
+		}
+	}
+
+	line := make([]rune, len(samples))
+	for i, sample := range samples {
+		level := 0
+		if max != 0 {
+			level = (sample*(len(sparkBlocks)-1) + max - 1) / max
+		}
+		line[i] = sparkBlocks[level]
+	}
+
+	return string(line)
+}
+
+func sum(samples []int) int {
+	total := 0
+	for _, sample := range samples {
+		total += sample
+	}
+
+	return total
+}
+
+// StatisticsWindow a Window that displays the ProductionStats of the Game
+type StatisticsWindow struct {
+	manager    WindowManager
+	gameWindow *GameWindow
+	widgets    []Widget
+}
+
+// NewStatisticsWindow creates a new StatisticsWindow, closing it returns to the GameWindow
+func NewStatisticsWindow(manager WindowManager, gameWindow *GameWindow) *StatisticsWindow {
+	var w StatisticsWindow
+	w.manager = manager
+	w.gameWindow = gameWindow
+
+	w.widgets = append(w.widgets, &StatisticsWidget{name: "Statistics", window: &w})
+
+	return &w
+}
+
+// Layout displays the StatisticsWindow
+func (w *StatisticsWindow) Layout(g *gocui.Gui) error {
+	maxX, maxY := g.Size()
+
+	g.Cursor = false
+
+	v, err := g.SetView("StatisticsWindow", 0, 0, maxX-1, maxY-1)
+	if err == nil || err == gocui.ErrUnknownView {
+		if _, err := g.SetViewOnTop("StatisticsWindow"); err != nil {
+			return err
+		}
+
+		v.Title = "Production statistics"
+	}
+
+	for _, widget := range w.widgets {
+		widget.Layout(g)
+	}
+
+	return nil
+}
+
+// StatisticsWidget displays the production and consumption sparklines of every Product
+type StatisticsWidget struct {
+	name   string
+	window *StatisticsWindow
+	span   int
+	offset int
+}
+
+// Layout displays the StatisticsWidget
+func (w *StatisticsWidget) Layout(g *gocui.Gui) error {
+	maxX, maxY := g.Size()
+
+	v, err := g.SetView(w.name, 1, 1, maxX-2, maxY-2)
+	if err != nil && err != gocui.ErrUnknownView {
+		return err
+	}
+
+	if err == gocui.ErrUnknownView {
+		if err := w.initBindings(g); err != nil {
+			return err
+		}
+	}
+
+	if _, err := g.SetCurrentView(w.name); err != nil {
+		return err
+	}
+
+	if _, err := g.SetViewOnTop(w.name); err != nil {
+		return err
+	}
+
+	v.Title = fmt.Sprintf("last %s", statsWindows[w.span].name)
+	v.Clear()
+
+	fmt.Fprintf(v, "window: ←→  scroll: ↑↓  close: %s\n", GlobalKeymap.Help(CommandCancel))
+
+	game := w.window.gameWindow.game
+	if game == nil {
+		return nil
+	}
+
+	width, height := v.Size()
+	// name, sign and total take 20 columns
+	width -= 20
+
+	stats := game.Stats()
+	lines := make([]string, 0)
+	for _, p := range GlobalProductFactory.cannonicalOrder {
+		produced := stats.Produced(w.span, p)
+		consumed := stats.Consumed(w.span, p)
+		if sum(produced) == 0 && sum(consumed) == 0 {
+			continue
+		}
+
+		lines = append(lines, fmt.Sprintf("%-11s+%7d \033[32m%s\033[0m", p.name, sum(produced), sparkline(produced, width)))
+		lines = append(lines, fmt.Sprintf("%-11s-%7d \033[31m%s\033[0m", "", sum(consumed), sparkline(consumed, width)))
+	}
+
+	if len(lines) == 0 {
+		fmt.Fprint(v, "nothing produced yet\n")
+		return nil
+	}
+
+	if w.offset > len(lines)-2 {
+		w.offset = len(lines) - 2
+	}
+
+	for i := w.offset; i < len(lines) && i-w.offset < height-1; i++ {
+		fmt.Fprintln(v, lines[i])
+	}
+
+	return nil
+}
+
+func (w *StatisticsWidget) initBindings(g *gocui.Gui) error {
+	if err := g.SetKeybinding(w.name, gocui.KeyArrowLeft, gocui.ModNone,
+		func(g *gocui.Gui, v *gocui.View) error {
+			if w.span > 0 {
+				w.span--
+			}
+
+			return nil
+		}); err != nil {
+		return err
+	}
+	if err := g.SetKeybinding(w.name, gocui.KeyArrowRight, gocui.ModNone,
+		func(g *gocui.Gui, v *gocui.View) error {
+			if w.span < len(statsWindows)-1 {
+				w.span++
+			}
+
+			return nil
+		}); err != nil {
+		return err
+	}
+	if err := g.SetKeybinding(w.name, gocui.KeyArrowUp, gocui.ModNone,
+		func(g *gocui.Gui, v *gocui.View) error {
+			if w.offset > 0 {
+				w.offset -= 2
+			}
+
+			return nil
+		}); err != nil {
+		return err
+	}
+	if err := g.SetKeybinding(w.name, gocui.KeyArrowDown, gocui.ModNone,
+		func(g *gocui.Gui, v *gocui.View) error {
+			w.offset += 2
+
+			return nil
+		}); err != nil {
+		return err
+	}
+	if err := GlobalKeymap.SetKeybinding(g, w.name, CommandCancel,
+		func(g *gocui.Gui, v *gocui.View) error {
+			w.window.manager.SetTopWindow(w.window.gameWindow)
+
+			return nil
+		}); err != nil {
+		return err
+	}
+
+	return nil
+}
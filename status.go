@@ -0,0 +1,158 @@
+package main
+
+const (
+	// StatusWorking the Structure is producing or moving Product-s
+	StatusWorking int = iota
+	// StatusStarved the Structure waits for input
+	StatusStarved
+	// StatusBlocked the Structure has a Product no one takes
+	StatusBlocked
+	// StatusIdle the Structure is disabled by the circuit network or has no Recipe
+	StatusIdle
+	statusCount
+)
+
+// StatusTicks the number of ticks over which the status of a Structure is aggregated
+const StatusTicks int = 200
+
+// statusNames the human readable names of the statuses
+var statusNames = []string{"working", "starved", "blocked", "idle"}
+
+// statusDisplayModes the DisplayMode used by the heatmap overlay for each status
+var statusDisplayModes = []DisplayMode{
+	DisplayModeStatusWorking,
+	DisplayModeStatusStarved,
+	DisplayModeStatusBlocked,
+	DisplayModeStatusIdle,
+}
+
+// StatusReporter a Structure that reports what it did during the last tick
+type StatusReporter interface {
+	Status() int
+}
+
+// Status reports the state of the Extractor after the last tick
+func (e *Extractor) Status() int {
+	if e.depleted {
+		return StatusStarved
+	}
+
+	if e.product != nil && e.counter == CycleSizeExtractor-1 {
+		return StatusBlocked
+	}
+
+	return StatusWorking
+}
+
+// Status reports the state of the Factory after the last tick
+func (f *Factory) Status() int {
+	if f.recipe == nil {
+		return StatusIdle
+	}
+
+	if f.counter == f.recipe.productionTicks {
+		return StatusBlocked
+	}
+
+	if f.counter > 0 {
+		return StatusWorking
+	}
+
+	return StatusStarved
+}
+
+// Status reports the state of the Belt after the last tick
+func (b *Belt) Status() int {
+	if b.Product == nil {
+		return StatusStarved
+	}
+
+	if b.Counter == CycleSizeBelt-1 {
+		return StatusBlocked
+	}
+
+	return StatusWorking
+}
+
+// Status reports the state of the Splitter after the last tick
+func (s *Splitter) Status() int {
+	return progressStatus(s.products, CycleSizeSplitter)
+}
+
+// Status reports the state of the Underground after the last tick
+func (u *Underground) Status() int {
+	return progressStatus(u.products, CycleSizeUnderground)
+}
+
+func progressStatus(products []ProductProgress, cycle int) int {
+	if len(products) == 0 {
+		return StatusStarved
+	}
+
+	if products[0].c == cycle-1 {
+		return StatusBlocked
+	}
+
+	return StatusWorking
+}
+
+// statusHistory the statuses reported by a Structure during the last StatusTicks ticks
+type statusHistory struct {
+	samples []int
+	next    int
+	counts  [statusCount]int
+}
+
+func (h *statusHistory) add(status int) {
+	if len(h.samples) < StatusTicks {
+		h.samples = append(h.samples, status)
+	} else {
+		h.counts[h.samples[h.next]]--
+		h.samples[h.next] = status
+		h.next = (h.next + 1) % StatusTicks
+	}
+
+	h.counts[status]++
+}
+
+// dominant returns the status reported the most
+func (h *statusHistory) dominant() int {
+	status := StatusWorking
+	for i, count := range h.counts {
+		if count > h.counts[status] {
+			status = i
+		}
+	}
+
+	return status
+}
+
+// recordStatus stores the status of the Structure after its tick, a disabled Structure is idle
+func (g *Game) recordStatus(s Structure, enabled bool) {
+	reporter, ok := s.(StatusReporter)
+	if !ok {
+		return
+	}
+
+	h, ok := g.statuses[s]
+	if !ok {
+		h = new(statusHistory)
+		g.statuses[s] = h
+	}
+
+	if enabled {
+		h.add(reporter.Status())
+	} else {
+		h.add(StatusIdle)
+	}
+}
+
+// StructureStatus returns the status reported the most by the Structure over the last StatusTicks ticks
+func (g *Game) StructureStatus(s Structure) (int, bool) {
+	h, ok := g.statuses[s]
+	if !ok {
+		return StatusIdle, false
+	}
+
+	return h.dominant(), true
+}
@@ -0,0 +1,314 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+const (
+	// themesDir the name of the directory holding the theme files, in the configuration directory
+	themesDir = "themes"
+	// themeExtension the extension of the theme files, other files of the directory are ignored
+	themeExtension = ".theme"
+
+	// displayModeCount the number of DisplayMode-s, each with its own structure color
+	displayModeCount = int(DisplayModeStatusIdle) + 1
+	// resourceColorCount the number of resource colors: copper, iron, stone and water
+	resourceColorCount = 4
+)
+
+// symbolRuneCounts the number of symbols expected for each symbolID, one for each rotation or amount
+var symbolRuneCounts = map[string]int{
+	"resource":         4,
+	"belt":             12,
+	"fillerCorner":     4,
+	"fillerMid":        4,
+	"fillerCenter":     1,
+	"input":            4,
+	"output":           4,
+	"chest":            1,
+	"splitterLeft":     4,
+	"splitterRight":    4,
+	"cornerTriangle":   4,
+	"undergroundEntry": 4,
+	"undergroundExit":  4,
+	"water":            1,
+	"pipe":             1,
+	"tank":             1,
+	"pump":             4,
+	"fluidInput":       4,
+	"rail":             1,
+	"station":          1,
+	"locomotive":       1,
+	"wagon":            1,
+	"providerChest":    1,
+	"requesterChest":   1,
+	"storageChest":     1,
+	"bot":              1,
+	"arithmetic":       1,
+	"decider":          1,
+	"invalid":          1,
+}
+
+// optionalSymbols the symbolID-s a theme can leave out, they have a default
+var optionalSymbols = map[string]bool{
+	"invalid": true,
+}
+
+// ReadTheme reads a theme file, one "name = value" per line with values optionally quoted, defining a ColorConfig,
+// a SymbolConfig or both; a missing configuration is returned as nil
+func ReadTheme(name string, r io.Reader) (*ColorConfig, *SymbolConfig, error) {
+	var structureColors []StructureColor
+	var resourceColors []Color
+	var resourceGradients [][]Color
+	var productColors []Color
+	types := make(map[string]string)
+	products := make(map[*Product]rune)
+
+	scanner := bufio.NewScanner(r)
+	for line := 1; scanner.Scan(); line++ {
+		text := strings.TrimSpace(scanner.Text())
+		if text == "" || strings.HasPrefix(text, "#") {
+			continue
+		}
+
+		separator := strings.Index(text, "=")
+		if separator == -1 {
+			return nil, nil, fmt.Errorf("line %d: expected name = value", line)
+		}
+
+		key := strings.TrimSpace(text[:separator])
+		value := strings.TrimSpace(text[separator+1:])
+
+		// symbols starting or ending with a space are quoted
+		if strings.HasPrefix(value, "\"") {
+			unquoted, err := strconv.Unquote(value)
+			if err != nil {
+				return nil, nil, fmt.Errorf("line %d: invalid quoted value %s", line, value)
+			}
+			value = unquoted
+		}
+
+		switch key {
+		case "name":
+			if value == "" {
+				return nil, nil, fmt.Errorf("line %d: empty name", line)
+			}
+			name = value
+		case "structure-colors":
+			fields := strings.Fields(value)
+			if len(fields) != displayModeCount {
+				return nil, nil, fmt.Errorf("line %d: expected %d structure colors, found %d", line, displayModeCount, len(fields))
+			}
+
+			structureColors = make([]StructureColor, len(fields))
+			for i, field := range fields {
+				color, err := parseStructureColor(field)
+				if err != nil {
+					return nil, nil, fmt.Errorf("line %d: %v", line, err)
+				}
+				structureColors[i] = color
+			}
+		case "resource-colors":
+			fields := strings.Fields(value)
+			if len(fields) != resourceColorCount {
+				return nil, nil, fmt.Errorf("line %d: expected %d resource colors, found %d", line, resourceColorCount, len(fields))
+			}
+
+			resourceColors = make([]Color, len(fields))
+			for i, field := range fields {
+				color, err := ParseColor(field)
+				if err != nil {
+					return nil, nil, fmt.Errorf("line %d: %v", line, err)
+				}
+				resourceColors[i] = color
+			}
+		case "resource-gradients":
+			fields := strings.Fields(value)
+			if len(fields) != resourceColorCount {
+				return nil, nil, fmt.Errorf("line %d: expected %d resource gradients, found %d", line, resourceColorCount, len(fields))
+			}
+
+			// "-" keeps the resource color
+			resourceGradients = make([][]Color, len(fields))
+			for i, field := range fields {
+				if field == "-" {
+					continue
+				}
+
+				parts := strings.Split(field, ":")
+				if len(parts) != 2 {
+					return nil, nil, fmt.Errorf("line %d: invalid gradient %q, expected poor:rich", line, field)
+				}
+
+				resourceGradients[i] = make([]Color, len(parts))
+				for j, part := range parts {
+					color, err := ParseColor(part)
+					if err != nil {
+						return nil, nil, fmt.Errorf("line %d: %v", line, err)
+					}
+					resourceGradients[i][j] = color
+				}
+			}
+		case "product-colors":
+			fields := strings.Fields(value)
+			if len(fields) == 0 {
+				return nil, nil, fmt.Errorf("line %d: no product colors", line)
+			}
+
+			productColors = make([]Color, len(fields))
+			for i, field := range fields {
+				color, err := ParseColor(field)
+				if err != nil {
+					return nil, nil, fmt.Errorf("line %d: %v", line, err)
+				}
+				productColors[i] = color
+			}
+		default:
+			if strings.HasPrefix(key, "product.") {
+				p := GlobalProductFactory.ProductByName(strings.TrimPrefix(key, "product."))
+				if p == nil {
+					return nil, nil, fmt.Errorf("line %d: unknown product %q", line, strings.TrimPrefix(key, "product."))
+				}
+				if utf8.RuneCountInString(value) != 1 {
+					return nil, nil, fmt.Errorf("line %d: %s expects a single glyph", line, key)
+				}
+
+				glyph, _ := utf8.DecodeRuneInString(value)
+				products[p] = glyph
+				continue
+			}
+
+			count, ok := symbolRuneCounts[key]
+			if !ok {
+				return nil, nil, fmt.Errorf("line %d: unknown symbol %q", line, key)
+			}
+			if n := utf8.RuneCountInString(value); n != count {
+				return nil, nil, fmt.Errorf("line %d: %s expects %d symbols, found %d", line, key, count, n)
+			}
+			types[key] = value
+		}
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, nil, err
+	}
+
+	var colorConfig *ColorConfig
+	if structureColors != nil || resourceColors != nil || resourceGradients != nil || productColors != nil {
+		if structureColors == nil || resourceColors == nil {
+			return nil, nil, fmt.Errorf("colors need both structure-colors and resource-colors")
+		}
+
+		colorConfig = &ColorConfig{name, structureColors, resourceColors, resourceGradients, productColors}
+	}
+
+	var symbolConfig *SymbolConfig
+	if len(products) > 0 && len(types) == 0 {
+		return nil, nil, fmt.Errorf("product glyphs need the symbols of the set")
+	}
+	if len(types) > 0 {
+		for id := range symbolRuneCounts {
+			if _, ok := types[id]; !ok && !optionalSymbols[id] {
+				return nil, nil, fmt.Errorf("missing symbol %q", id)
+			}
+		}
+
+		symbolConfig = &SymbolConfig{name, types, products}
+	}
+
+	if colorConfig == nil && symbolConfig == nil {
+		return nil, nil, fmt.Errorf("neither colors nor symbols defined")
+	}
+
+	return colorConfig, symbolConfig, nil
+}
+
+// parseStructureColor parses a structure color, a Color and an SGR attribute separated by ";" as in "31;1"
+func parseStructureColor(text string) (StructureColor, error) {
+	parts := strings.Split(text, ";")
+	if len(parts) != 2 {
+		return StructureColor{}, fmt.Errorf("invalid color %q, expected color;attribute", text)
+	}
+
+	color, err := ParseColor(parts[0])
+	if err != nil {
+		return StructureColor{}, err
+	}
+
+	attribute, err := strconv.Atoi(parts[1])
+	if err != nil || attribute < 0 || attribute > 9 {
+		return StructureColor{}, fmt.Errorf("invalid attribute %q", text)
+	}
+
+	return StructureColor{color, attribute}, nil
+}
+
+// LoadThemes adds the themes of the directory to the DisplayConfigManager, a missing directory has no themes; the
+// themes that cannot be loaded are skipped and their errors returned
+func (m *DisplayConfigManager) LoadThemes(dir string) []error {
+	entries, err := ioutil.ReadDir(dir)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return []error{err}
+	}
+
+	var errs []error
+	for _, entry := range entries {
+		if entry.IsDir() || filepath.Ext(entry.Name()) != themeExtension {
+			continue
+		}
+
+		path := filepath.Join(dir, entry.Name())
+		if err := m.loadTheme(path, strings.TrimSuffix(entry.Name(), themeExtension)); err != nil {
+			errs = append(errs, fmt.Errorf("%s: %v", path, err))
+		}
+	}
+
+	return errs
+}
+
+// loadTheme reads a theme file, its name defaults to the name of the file; nothing is added if any of its
+// configurations cannot be
+func (m *DisplayConfigManager) loadTheme(path, name string) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	colorConfig, symbolConfig, err := ReadTheme(name, f)
+	if err != nil {
+		return err
+	}
+
+	if colorConfig != nil && m.ColorConfigIndex(colorConfig.Name) != -1 {
+		return fmt.Errorf("color configuration %q already exists", colorConfig.Name)
+	}
+
+	if symbolConfig != nil {
+		if m.SymbolConfigIndex(symbolConfig.Name) != -1 {
+			return fmt.Errorf("symbol configuration %q already exists", symbolConfig.Name)
+		}
+		// the last check, the glyphs are kept once set
+		if err := GlobalProductFactory.SetGlyphs(symbolConfig.Name, symbolConfig.Products); err != nil {
+			return err
+		}
+		m.SymbolConfigs = append(m.SymbolConfigs, symbolConfig)
+	}
+
+	if colorConfig != nil {
+		m.ColorConfigs = append(m.ColorConfigs, colorConfig)
+	}
+
+	return nil
+}
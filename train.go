@@ -0,0 +1,467 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+)
+
+const (
+	// CycleSizeTrain the number of ticks a Train needs to move one tile
+	CycleSizeTrain int = 5
+	// WagonMaxStorage the maximum number of products stored in a cargo wagon
+	WagonMaxStorage int = 200
+	// TrainMaxWagons the maximum number of cargo wagons pulled by a locomotive
+	TrainMaxWagons int = 4
+	// StationMinWaitTicks the minimum number of ticks a Train waits in a station
+	StationMinWaitTicks int = 20
+)
+
+// RailTile is the map representation of a rail
+type RailTile struct {
+	BaseStructureTile
+}
+
+// NewRailTile creates a new *RailTile
+func NewRailTile() *RailTile {
+	return &RailTile{BaseStructureTile{0, 1, "rail", nil, nil, nil}}
+}
+
+// StationTile is the map representation of a train station
+type StationTile struct {
+	BaseStructureTile
+}
+
+// NewStationTile creates a new *StationTile
+func NewStationTile() *StationTile {
+	return &StationTile{BaseStructureTile{0, 1, "station", nil, nil, nil}}
+}
+
+// BaseRailStructure a Structure that Train-s can move on, it does not handle Product-s
+type BaseRailStructure struct {
+	BaseStructure
+}
+
+// Tick does nothing for BaseRailStructure, Train-s are handled by the Game
+func (s *BaseRailStructure) Tick() {
+}
+
+// CanRetrieveProduct does nothing for BaseRailStructure, it does not handle Product-s
+func (s *BaseRailStructure) CanRetrieveProduct() (*Product, bool) {
+	return nil, false
+}
+
+// RetrieveProduct does nothing for BaseRailStructure, it does not handle Product-s
+func (s *BaseRailStructure) RetrieveProduct() (*Product, bool) {
+	return nil, false
+}
+
+// CanAcceptProduct does nothing for BaseRailStructure, it does not handle Product-s
+func (s *BaseRailStructure) CanAcceptProduct(*Product) bool {
+	return false
+}
+
+// AcceptProduct does nothing for BaseRailStructure, it does not handle Product-s
+func (s *BaseRailStructure) AcceptProduct(*Product) bool {
+	return false
+}
+
+// RotateRight does nothing for a BaseRailStructure
+func (s *BaseRailStructure) RotateRight() {
+}
+
+// RotateLeft does nothing for a BaseRailStructure
+func (s *BaseRailStructure) RotateLeft() {
+}
+
+// Rail Structure that connects to the neighbouring Rail-s and Station-s
+type Rail struct {
+	BaseRailStructure
+}
+
+// NewRail creates a new *Rail
+func NewRail() *Rail {
+	block := new(Rail)
+	block.tiles = [][]StructureTile{
+		{NewRailTile()},
+	}
+
+	block.inputs = make([]Transfer, 0)
+	block.outputs = make([]Transfer, 0)
+
+	return block
+}
+
+// CopyStructure creates a copy of the Rail
+func (r *Rail) CopyStructure() Structure {
+	rail := new(Rail)
+	rail.BaseStructure = *r.BaseStructure.copyStructure(rail)
+
+	return rail
+}
+
+// GetCode return the code for the Rail type
+func (*Rail) GetCode() int {
+	return ProductStructureRail
+}
+
+// Station Structure on the rail network where Train-s load and unload the neighbouring Chest-s
+type Station struct {
+	BaseRailStructure
+	id   int
+	name string
+}
+
+// NewStation creates a new *Station
+func NewStation() *Station {
+	block := new(Station)
+	block.tiles = [][]StructureTile{
+		{NewStationTile()},
+	}
+
+	block.inputs = make([]Transfer, 0)
+	block.outputs = make([]Transfer, 0)
+
+	return block
+}
+
+// CopyStructure creates a copy of the Station
+func (s *Station) CopyStructure() Structure {
+	station := new(Station)
+	station.BaseStructure = *s.BaseStructure.copyStructure(station)
+
+	return station
+}
+
+// Name returns the name of the Station
+func (s *Station) Name() string {
+	return s.name
+}
+
+// GetCode return the code for the Station type
+func (*Station) GetCode() int {
+	return ProductStructureStation
+}
+
+// ScheduleEntry a Station a Train visits and what it does there
+type ScheduleEntry struct {
+	station *Station
+	load    bool
+}
+
+// Train a locomotive pulling cargo wagons along the rails
+type Train struct {
+	name     string
+	wagons   int
+	cargo    *Storage
+	schedule []ScheduleEntry
+	next     int
+
+	pos   position
+	trail []position
+	path  []position
+
+	counter int
+	waiting int
+
+	locomotiveTile *BaseStructureTile
+	wagonTile      *BaseStructureTile
+}
+
+// NewTrain creates a new *Train with the specified number of cargo wagons
+func NewTrain(name string, wagons int, pos position) *Train {
+	t := new(Train)
+	t.name = name
+	t.wagons = wagons
+	t.cargo = NewStorage(wagons * WagonMaxStorage)
+	t.schedule = make([]ScheduleEntry, 0)
+	t.pos = pos
+	t.trail = make([]position, 0)
+
+	t.locomotiveTile = &BaseStructureTile{0, 1, "locomotive", nil, nil, nil}
+	t.wagonTile = &BaseStructureTile{0, 1, "wagon", nil, nil, nil}
+
+	return t
+}
+
+// Name returns the name of the Train
+func (t *Train) Name() string {
+	return t.name
+}
+
+// Schedule returns the Station-s visited by the Train
+func (t *Train) Schedule() []ScheduleEntry {
+	return t.schedule
+}
+
+// SetSchedule replaces the Station-s visited by the Train
+func (t *Train) SetSchedule(schedule []ScheduleEntry) {
+	t.schedule = schedule
+	t.next = 0
+	t.waiting = 0
+	t.path = nil
+}
+
+// Tiles returns the Tile-s to be displayed for the Train, indexed by their map position
+func (t *Train) Tiles() map[position]Tile {
+	tiles := make(map[position]Tile)
+	for _, p := range t.trail {
+		tiles[p] = t.wagonTile
+	}
+	tiles[t.pos] = t.locomotiveTile
+
+	return tiles
+}
+
+// Tick advances the Train towards the next Station of its schedule
+func (t *Train) Tick(g *Game) {
+	if len(t.schedule) == 0 {
+		return
+	}
+
+	t.next %= len(t.schedule)
+	entry := t.schedule[t.next]
+
+	target, exists := g.stations[entry.station]
+	if !exists {
+		// the station was removed, skip it
+		t.advance()
+		return
+	}
+
+	if t.pos == target {
+		t.waiting++
+		moved := t.transfer(g, entry)
+		if !moved && t.waiting >= StationMinWaitTicks {
+			t.advance()
+		}
+		return
+	}
+
+	t.counter++
+	if t.counter < CycleSizeTrain {
+		return
+	}
+	t.counter = 0
+
+	if len(t.path) == 0 || !g.isRailAt(t.path[0]) {
+		t.path = g.findRailPath(t.pos, target)
+		if len(t.path) == 0 {
+			// no route to the station, wait for the rails to be fixed
+			return
+		}
+	}
+
+	t.trail = append([]position{t.pos}, t.trail...)
+	if len(t.trail) > t.wagons {
+		t.trail = t.trail[:t.wagons]
+	}
+
+	t.pos = t.path[0]
+	t.path = t.path[1:]
+}
+
+func (t *Train) advance() {
+	t.next = (t.next + 1) % len(t.schedule)
+	t.waiting = 0
+	t.path = nil
+}
+
+// transfer moves one Product between the cargo wagons and the Chest-s next to the Station
+func (t *Train) transfer(g *Game, entry ScheduleEntry) bool {
+	neighbours := []position{
+		{t.pos.x, t.pos.y + 1},
+		{t.pos.x - 1, t.pos.y},
+		{t.pos.x, t.pos.y - 1},
+		{t.pos.x + 1, t.pos.y},
+	}
+
+	for _, n := range neighbours {
+		s, _, _ := g.GetStructureAt(n.y, n.x)
+		chest, ok := s.(*Chest)
+		if !ok {
+			continue
+		}
+
+		if entry.load {
+			for _, product := range GlobalProductFactory.cannonicalOrder {
+				if chest.s.objects[product] <= 0 {
+					continue
+				}
+
+				if t.cargo.Size() < t.cargo.Capacity() && t.cargo.Add(product, 1) == 1 {
+					chest.s.Remove(product, 1)
+					return true
+				}
+				break
+			}
+		} else {
+			for _, product := range GlobalProductFactory.cannonicalOrder {
+				if t.cargo.objects[product] <= 0 {
+					continue
+				}
+
+				if chest.AcceptProduct(product) {
+					t.cargo.Remove(product, 1)
+					return true
+				}
+				break
+			}
+		}
+	}
+
+	return false
+}
+
+// Stations returns the Station-s on the map, in the order they were built
+func (g *Game) Stations() []*Station {
+	stations := make([]*Station, 0, len(g.stations))
+	for s := range g.stations {
+		stations = append(stations, s)
+	}
+
+	sort.Slice(stations, func(i, j int) bool {
+		return stations[i].id < stations[j].id
+	})
+
+	return stations
+}
+
+func (g *Game) addStation(s *Station, p position) {
+	if s.id == 0 {
+		// a Station placed again after an undo keeps its name
+		g.stationCount++
+		s.id = g.stationCount
+		s.name = fmt.Sprintf("Station %d", s.id)
+	}
+	g.stations[s] = p
+}
+
+// AddTrain creates a Train at the Station on the cursor, using the locomotive and wagons in the inventory
+func (g *Game) AddTrain() *Train {
+	x, y := g.GetCursor()
+	s, _, _ := g.GetStructureAt(y, x)
+	if _, ok := s.(*Station); !ok {
+		return nil
+	}
+
+	if g.GetTrainAt(y, x) != nil {
+		return nil
+	}
+
+	locomotive := GlobalProductFactory.GetProduct(ProductLocomotive)
+	if g.inventory.Remove(locomotive, 1) != 1 {
+		return nil
+	}
+
+	wagons := g.inventory.Remove(GlobalProductFactory.GetProduct(ProductWagon), TrainMaxWagons)
+
+	g.trainCount++
+	t := NewTrain(fmt.Sprintf("Train %d", g.trainCount), wagons, position{x: x, y: y})
+	g.trains = append(g.trains, t)
+
+	return t
+}
+
+// RemoveTrain removes the Train from the map, returning it and its cargo to the inventory, the Train stays in
+// place if they do not fit
+func (g *Game) RemoveTrain(t *Train) bool {
+	for i, train := range g.trains {
+		if train != t {
+			continue
+		}
+
+		if g.inventory.Capacity()-g.inventory.Size() < 1+t.wagons+t.cargo.Size() {
+			return false
+		}
+
+		g.trains = append(g.trains[:i], g.trains[i+1:]...)
+
+		g.inventory.Add(GlobalProductFactory.GetProduct(ProductLocomotive), 1)
+		if t.wagons > 0 {
+			g.inventory.Add(GlobalProductFactory.GetProduct(ProductWagon), t.wagons)
+		}
+		for product, count := range t.cargo.objects {
+			g.inventory.Add(product, count)
+		}
+
+		return true
+	}
+
+	return false
+}
+
+// GetTrainAt returns the Train covering the location
+func (g *Game) GetTrainAt(y, x int) *Train {
+	p := position{x: x, y: y}
+	for _, t := range g.trains {
+		if _, ok := t.Tiles()[p]; ok {
+			return t
+		}
+	}
+
+	return nil
+}
+
+// underTrain indicates if a Train covers a tile of the Structure placed at the location, its rails cannot be removed
+func (g *Game) underTrain(s Structure, y, x int) bool {
+	for yy, tiles := range s.Tiles() {
+		for xx := range tiles {
+			if g.GetTrainAt(y+yy, x+xx) != nil {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+func (g *Game) isRailAt(p position) bool {
+	s, _, _ := g.GetStructureAt(p.y, p.x)
+	switch s.(type) {
+	case *Rail, *Station:
+		return true
+	}
+
+	return false
+}
+
+// findRailPath returns the shortest path on the rails between two positions, excluding the start
+func (g *Game) findRailPath(start, end position) []position {
+	previous := map[position]position{start: start}
+	queue := []position{start}
+
+	for len(queue) != 0 {
+		crt := queue[0]
+		queue = queue[1:]
+
+		if crt == end {
+			path := make([]position, 0)
+			for p := end; p != start; p = previous[p] {
+				path = append([]position{p}, path...)
+			}
+			return path
+		}
+
+		neighbours := []position{
+			{crt.x, crt.y + 1},
+			{crt.x - 1, crt.y},
+			{crt.x, crt.y - 1},
+			{crt.x + 1, crt.y},
+		}
+
+		for _, n := range neighbours {
+			if _, visited := previous[n]; visited {
+				continue
+			}
+
+			if !g.isRailAt(n) {
+				continue
+			}
+
+			previous[n] = crt
+			queue = append(queue, n)
+		}
+	}
+
+	return nil
+}
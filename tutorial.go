@@ -0,0 +1,234 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/jroimartin/gocui"
+)
+
+const (
+	// TutorialAreaWidth the width of the area cleared for the tutorial, in the top left corner of the map
+	TutorialAreaWidth int = 40
+	// TutorialAreaHeight the height of the area cleared for the tutorial
+	TutorialAreaHeight int = 25
+	// TutorialCopperAmount the amount of each tile of the copper patch of the tutorial
+	TutorialCopperAmount int = 300
+)
+
+// TutorialStep an instruction of the Tutorial, done once the Game reaches the expected state
+type TutorialStep struct {
+	text func() string
+	done func(g *Game) bool
+}
+
+// Tutorial walks a new player through a first production line, one step at a time
+type Tutorial struct {
+	steps   []TutorialStep
+	current int
+}
+
+// NewTutorial creates a new *Tutorial, the instructions use the keys of the GlobalKeymap
+func NewTutorial() *Tutorial {
+	k := GlobalKeymap
+	moves := func() string {
+		return k.Help(CommandUp, CommandLeft, CommandDown, CommandRight)
+	}
+
+	t := new(Tutorial)
+	t.steps = []TutorialStep{
+		{
+			func() string {
+				return fmt.Sprintf("Copper ore lies next to the cursor. Press %s, choose the extractor with ↑↓ and %s, "+
+					"move it over the ore with %s, rotate it with %s and place it with %s.",
+					k.Help(CommandAdd), k.Help(CommandConfirm), moves(), k.Help(CommandRotateLeft, CommandRotateRight),
+					k.Help(CommandConfirm))
+			},
+			func(g *Game) bool {
+				return g.hasStructure(func(s Structure) bool {
+					_, ok := s.(*Extractor)
+					return ok
+				})
+			},
+		},
+		{
+			func() string {
+				return fmt.Sprintf("The extractor pushes ore out of its arrow. Place a factory a few tiles away "+
+					"from it: %s, choose the factory and place it with %s.", k.Help(CommandAdd), k.Help(CommandConfirm))
+			},
+			func(g *Game) bool {
+				return g.hasStructure(func(s Structure) bool {
+					_, ok := s.(*Factory)
+					return ok
+				})
+			},
+		},
+		{
+			func() string {
+				return fmt.Sprintf("A factory produces a single recipe. Choose copper wire with ↑↓ and press %s.",
+					k.Help(CommandConfirm))
+			},
+			func(g *Game) bool {
+				return g.hasStructure(func(s Structure) bool {
+					f, ok := s.(*Factory)
+					return ok && f.recipe != nil
+				})
+			},
+		},
+		{
+			func() string {
+				return fmt.Sprintf("Belt the ore to the factory: move in front of the extractor arrow, press %s, "+
+					"draw the path to a factory input with %s and place it with %s. Press %s to cancel.",
+					k.Help(CommandBelts), moves(), k.Help(CommandConfirm), k.Help(CommandCancel))
+			},
+			func(g *Game) bool {
+				stats := g.Stats()
+				for _, p := range GlobalProductFactory.cannonicalOrder {
+					if sum(stats.Consumed(0, p)) > 0 {
+						return true
+					}
+				}
+
+				return false
+			},
+		},
+		{
+			func() string {
+				return fmt.Sprintf("The factory is working. Belt its output to a chest to collect the wire, "+
+					"the chest is in the %s menu too.", k.Help(CommandAdd))
+			},
+			func(g *Game) bool {
+				return g.hasStructure(func(s Structure) bool {
+					c, ok := s.(*Chest)
+					if !ok {
+						return false
+					}
+
+					for _, recipe := range GlobalRecipeFactory.Assembly {
+						if c.s.objects[recipe.output] > 0 {
+							return true
+						}
+					}
+
+					return false
+				})
+			},
+		},
+	}
+
+	return t
+}
+
+// Update advances over the steps completed in the Game
+func (t *Tutorial) Update(g *Game) {
+	for t.current < len(t.steps) && t.steps[t.current].done(g) {
+		t.current++
+	}
+}
+
+// Done indicates if every step was completed
+func (t *Tutorial) Done() bool {
+	return t.current >= len(t.steps)
+}
+
+// Text returns the instructions of the current step
+func (t *Tutorial) Text() string {
+	if t.Done() {
+		return fmt.Sprintf("Done! The first production line is running. Press %s for the help of every state.",
+			GlobalKeymap.Help(CommandHelp))
+	}
+
+	return t.steps[t.current].text()
+}
+
+// Progress returns the number of the current step and the number of steps
+func (t *Tutorial) Progress() (int, int) {
+	return t.current + 1, len(t.steps)
+}
+
+// hasStructure indicates if a Structure on the map matches
+func (g *Game) hasStructure(match func(s Structure) bool) bool {
+	structures, _ := g.areaStructures(position{}, position{x: len(g.WorldMap[0]) - 1, y: len(g.WorldMap) - 1})
+	for _, s := range structures {
+		if match(s) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// NewTutorialGame creates the Game of the tutorial: an open area around the cursor with a single copper patch
+func NewTutorialGame() *Game {
+	g := GenerateGame(120, 100)
+
+	for y := 0; y < TutorialAreaHeight; y++ {
+		for x := 0; x < TutorialAreaWidth; x++ {
+			g.WorldMap[y][x] = &RawResource{0, -1}
+		}
+	}
+
+	for y := 3; y < 9; y++ {
+		for x := 4; x < 10; x++ {
+			g.WorldMap[y][x] = &RawResource{TutorialCopperAmount, ProductResourceCopper}
+		}
+	}
+
+	return g
+}
+
+// TutorialWidget a GameWidget that displays the current step of the Tutorial at the bottom of the map
+type TutorialWidget struct {
+	name      string
+	reservedX int
+	height    int
+
+	game *Game
+	s    *state
+}
+
+func newTutorialWidget() *TutorialWidget {
+	w := new(TutorialWidget)
+
+	return w
+}
+
+// SetGame sets the Game associated with TutorialWidget
+func (w *TutorialWidget) SetGame(game *Game) {
+	w.game = game
+}
+
+// Layout displays the TutorialWidget
+func (w *TutorialWidget) Layout(g *gocui.Gui) error {
+	if w.s.tutorial == nil {
+		return nil
+	}
+
+	maxX, maxY := g.Size()
+
+	v, err := g.SetView(w.name, 0, maxY-1-w.height, maxX-1-w.reservedX, maxY-1)
+	if err != nil && err != gocui.ErrUnknownView {
+		return err
+	}
+
+	if err == gocui.ErrUnknownView {
+		v.Wrap = true
+	}
+
+	if _, err := g.SetViewOnTop(w.name); err != nil {
+		return err
+	}
+
+	w.s.tutorial.Update(w.game)
+
+	step, steps := w.s.tutorial.Progress()
+	if w.s.tutorial.Done() {
+		v.Title = w.name
+	} else {
+		v.Title = fmt.Sprintf("%s - step %d/%d", w.name, step, steps)
+	}
+
+	v.Clear()
+	fmt.Fprintln(v, w.s.tutorial.Text())
+
+	return nil
+}